@@ -23,5 +23,7 @@ func main() {
 		return
 	}
 
+	defer server.Supervisor.Close()
+
 	server.App.Listen(":3000")
 }
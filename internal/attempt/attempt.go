@@ -0,0 +1,236 @@
+package attempt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/utils"
+)
+
+type FullAttempt struct {
+	entities.Attempt
+	Answers []entities.AttemptAnswer `json:"answers"`
+}
+
+// Persist an attempt together with its per-question breakdown in a single transaction.
+func CreateAttempt(ctx context.Context, db *sql.DB, att entities.Attempt, answers []entities.AttemptAnswer) (*FullAttempt, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	cols := []string{"id", "sessionID", "mockID", "userID", "totalMarks", "maxMarks", "startedAt", "submittedAt"}
+	placeholders := make([]string, len(cols))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+
+	stmt := fmt.Sprintf(`INSERT INTO attempt (%s) VALUES (%s)`, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+	vals := []any{att.ID, att.SessionID, att.MockID, att.UserID, att.TotalMarks, att.MaxMarks, att.StartedAt, att.SubmittedAt}
+
+	if _, err := tx.ExecContext(ctx, stmt, vals...); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	ansStmt := `INSERT INTO attemptAnswer (id, attemptID, questionID, selectedOptionID, response, isCorrect, points) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	for _, a := range answers {
+		vals := []any{a.ID, a.AttemptID, a.QuestionID, a.SelectedOptionID, a.Response, a.IsCorrect, a.Points}
+		if _, err := tx.ExecContext(ctx, ansStmt, vals...); err != nil {
+			return nil, data.SQLiteErrorComparator(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	return &FullAttempt{
+		Attempt: att,
+		Answers: answers,
+	}, nil
+}
+
+// List attempts, newest first. Empty userID or mockID means "any".
+func ListAttempts(ctx context.Context, db *sql.DB, userID string, mockID string) ([]entities.Attempt, error) {
+	stmt := `
+        SELECT id, sessionID, mockID, userID, totalMarks, maxMarks, startedAt, submittedAt
+        FROM attempt
+    `
+	where := []string{}
+	args := []any{}
+
+	if userID != "" {
+		where = append(where, "userID = ?")
+		args = append(args, userID)
+	}
+	if mockID != "" {
+		where = append(where, "mockID = ?")
+		args = append(args, mockID)
+	}
+	if len(where) > 0 {
+		stmt += "WHERE " + strings.Join(where, " AND ")
+	}
+	stmt += " ORDER BY submittedAt DESC"
+
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	attempts := []entities.Attempt{}
+	for rows.Next() {
+		att, err := scanAttempt(rows)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, *att)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	return attempts, nil
+}
+
+// How many attempts the user submitted at the mock, and when the last one was.
+func History(ctx context.Context, db *sql.DB, userID string, mockID string) (int, *time.Time, error) {
+	stmt := `
+        SELECT COUNT(*), MAX(submittedAt)
+        FROM attempt
+        WHERE userID = ? AND mockID = ?
+    `
+	var count int
+	var lastStr sql.NullString
+	if err := db.QueryRowContext(ctx, stmt, userID, mockID).Scan(&count, &lastStr); err != nil {
+		return 0, nil, data.SQLiteErrorComparator(err)
+	}
+
+	if !lastStr.Valid {
+		return count, nil, nil
+	}
+	last, err := utils.ParseTime(lastStr.String)
+	if err != nil {
+		return 0, nil, errs.NewError(err, errs.DataErrorType, errs.ErrInternalFailure)
+	}
+	return count, last, nil
+}
+
+// IDs of the questions the user was given across their attempts at the mock.
+func AttemptedQuestionIDs(ctx context.Context, db *sql.DB, userID string, mockID string) ([]string, error) {
+	stmt := `
+        SELECT DISTINCT a.questionID
+        FROM attemptAnswer a
+        JOIN attempt t ON t.id = a.attemptID
+        WHERE t.userID = ? AND t.mockID = ?
+    `
+	rows, err := db.QueryContext(ctx, stmt, userID, mockID)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	return ids, nil
+}
+
+func GetAttempt(ctx context.Context, db *sql.DB, id string) (*FullAttempt, error) {
+	stmt := `
+        SELECT id, sessionID, mockID, userID, totalMarks, maxMarks, startedAt, submittedAt
+        FROM attempt
+        WHERE id = ?
+    `
+	att, err := scanAttempt(db.QueryRowContext(ctx, stmt, id))
+	if err != nil {
+		return nil, err
+	}
+
+	// Answers are inserted in the mock's question order, which the rowid keeps.
+	ansStmt := `
+        SELECT id, attemptID, questionID, selectedOptionID, response, isCorrect, points
+        FROM attemptAnswer
+        WHERE attemptID = ?
+        ORDER BY rowid
+    `
+	rows, err := db.QueryContext(ctx, ansStmt, att.ID)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	answers := []entities.AttemptAnswer{}
+	for rows.Next() {
+		var a entities.AttemptAnswer
+		var selectedOptionID sql.NullString
+		if err := rows.Scan(&a.ID, &a.AttemptID, &a.QuestionID, &selectedOptionID, &a.Response, &a.IsCorrect, &a.Points); err != nil {
+			return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+		}
+		a.SelectedOptionID = selectedOptionID.String
+		answers = append(answers, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	return &FullAttempt{
+		Attempt: *att,
+		Answers: answers,
+	}, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAttempt(row scanner) (*entities.Attempt, error) {
+	var att entities.Attempt
+	var startedAtStr, submittedAtStr string
+
+	err := row.Scan(
+		&att.ID,
+		&att.SessionID,
+		&att.MockID,
+		&att.UserID,
+		&att.TotalMarks,
+		&att.MaxMarks,
+		&startedAtStr,
+		&submittedAtStr,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.NewError(err, errs.DataErrorType, errs.ErrNotFound)
+		}
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	startedAt, err := utils.ParseTime(startedAtStr)
+	if err != nil {
+		return nil, errs.NewError(err, errs.DataErrorType, errs.ErrInternalFailure)
+	}
+	submittedAt, err := utils.ParseTime(submittedAtStr)
+	if err != nil {
+		return nil, errs.NewError(err, errs.DataErrorType, errs.ErrInternalFailure)
+	}
+
+	att.StartedAt = *startedAt
+	att.SubmittedAt = *submittedAt
+
+	return &att, nil
+}
@@ -0,0 +1,142 @@
+package attempt_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+func TestAttemptStore(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "u1")
+	testutil.CreateMocks(t, db, "m1")
+
+	submittedAt := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+	att := entities.Attempt{
+		ID: "a1", SessionID: "s1", MockID: "m1", UserID: "u1",
+		TotalMarks: 1.5, MaxMarks: 3, StartedAt: submittedAt.Add(-20 * time.Minute), SubmittedAt: submittedAt,
+	}
+	answers := []entities.AttemptAnswer{
+		{ID: "aa1", AttemptID: "a1", QuestionID: "q1", SelectedOptionID: "o1", Response: `["o1"]`, IsCorrect: true, Points: 2},
+		{ID: "aa2", AttemptID: "a1", QuestionID: "q2", Response: `["o3","o4"]`, Points: -0.5},
+		{ID: "aa0", AttemptID: "a1", QuestionID: "q0"},
+	}
+	if _, err := attempt.CreateAttempt(ctx, db, att, answers); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := attempt.GetAttempt(ctx, db, "a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SessionID != "s1" || got.TotalMarks != 1.5 || got.MaxMarks != 3 || !got.StartedAt.Equal(att.StartedAt) || !got.SubmittedAt.Equal(submittedAt) {
+		t.Errorf("expected the attempt back as stored, got %+v", got.Attempt)
+	}
+	var order []string
+	for _, a := range got.Answers {
+		order = append(order, a.QuestionID)
+	}
+	if !slices.Equal(order, []string{"q1", "q2", "q0"}) {
+		t.Fatalf("expected the answers in the order they were recorded, got %v", order)
+	}
+	byQuestion := map[string]entities.AttemptAnswer{}
+	for _, a := range got.Answers {
+		byQuestion[a.QuestionID] = a
+	}
+	if a := byQuestion["q1"]; a.SelectedOptionID != "o1" || !a.IsCorrect || a.Points != 2 {
+		t.Errorf("expected the single answer back as stored, got %+v", a)
+	}
+	if a := byQuestion["q2"]; a.SelectedOptionID != "" || a.Response != `["o3","o4"]` || a.IsCorrect || a.Points != -0.5 {
+		t.Errorf("expected the multiple answer back as stored, got %+v", a)
+	}
+
+	attempted, err := attempt.AttemptedQuestionIDs(ctx, db, "u1", "m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(attempted)
+	if !slices.Equal(attempted, []string{"q0", "q1", "q2"}) {
+		t.Errorf("expected every question of the attempt, got %v", attempted)
+	}
+	if attempted, err := attempt.AttemptedQuestionIDs(ctx, db, "u2", "m1"); err != nil || len(attempted) != 0 {
+		t.Errorf("expected no questions for another user, got %v (%v)", attempted, err)
+	}
+
+	// One attempt per session, whoever submits it first.
+	dup := att
+	dup.ID = "a2"
+	_, err = attempt.CreateAttempt(ctx, db, dup, nil)
+	if !errors.Is(err, errs.Error{Code: errs.ErrAlreadyExists, Type: errs.SQLErrorType.String()}) {
+		t.Errorf("expected a second attempt at the same session to be refused, got %v", err)
+	}
+
+	_, err = attempt.GetAttempt(ctx, db, "missing")
+	if !errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.DataErrorType.String()}) {
+		t.Errorf("expected a missing attempt to be not found, got %v", err)
+	}
+}
+
+func TestListAttempts(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "u1", "u2")
+	testutil.CreateMocks(t, db, "m1", "m2")
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i, a := range []struct{ id, mockID, userID string }{{"a1", "m1", "u1"}, {"a2", "m1", "u2"}, {"a3", "m2", "u1"}, {"a4", "m1", "u1"}} {
+		submittedAt := base.Add(time.Duration(i) * time.Hour)
+		att := entities.Attempt{ID: a.id, SessionID: a.id, MockID: a.mockID, UserID: a.userID, StartedAt: submittedAt.Add(-time.Minute), SubmittedAt: submittedAt}
+		if _, err := attempt.CreateAttempt(ctx, db, att, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(userID string, mockID string) []string {
+		attempts, err := attempt.ListAttempts(ctx, db, userID, mockID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(attempts))
+		for i, a := range attempts {
+			ids[i] = a.ID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		userID, mockID string
+		want           []string
+	}{
+		{"", "", []string{"a4", "a3", "a2", "a1"}},
+		{"u1", "", []string{"a4", "a3", "a1"}},
+		{"", "m1", []string{"a4", "a2", "a1"}},
+		{"u1", "m1", []string{"a4", "a1"}},
+		{"u3", "", []string{}},
+	}
+	for _, tt := range tests {
+		if got := ids(tt.userID, tt.mockID); !slices.Equal(got, tt.want) {
+			t.Errorf("ListAttempts(%q, %q) = %v, expected %v newest first", tt.userID, tt.mockID, got, tt.want)
+		}
+	}
+
+	count, last, err := attempt.History(ctx, db, "u1", "m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || last == nil || !last.Equal(base.Add(3*time.Hour)) {
+		t.Errorf("expected 2 attempts, the last at %v, got %d at %v", base.Add(3*time.Hour), count, last)
+	}
+
+	count, last, err = attempt.History(ctx, db, "u2", "m2")
+	if err != nil || count != 0 || last != nil {
+		t.Errorf("expected no history, got %d at %v (%v)", count, last, err)
+	}
+}
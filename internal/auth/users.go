@@ -45,6 +45,7 @@ func CreateUser(ctx context.Context, db *sql.DB, userData schemas.UserCreateRequ
 		Name:         userData.Name,
 		Email:        userData.Email,
 		PasswordHash: string(bytes),
+		Role:         entities.RoleUser,
 
 		CreatedAt:     time.Now(),
 		LastUpdatedAt: time.Now(),
@@ -63,7 +64,7 @@ func CreateUser(ctx context.Context, db *sql.DB, userData schemas.UserCreateRequ
 }
 
 func GetUser(ctx context.Context, db *sql.DB, req schemas.UserFetchRequest) (*entities.User, error) {
-	query := `SELECT id, name, email, passwordHash, createdAt, lastUpdatedAt FROM user WHERE `
+	query := `SELECT id, name, email, passwordHash, role, createdAt, lastUpdatedAt FROM user WHERE `
 	var identifier string
 
 	if req.ID != "" {
@@ -83,11 +84,12 @@ func GetUser(ctx context.Context, db *sql.DB, req schemas.UserFetchRequest) (*en
 		name         string
 		email        string
 		passwordHash string
+		role         string
 
 		createdAtStr string
 		updatedAtStr string 
 	)
-	err := row.Scan(&id, &name, &email, &passwordHash, &createdAtStr, &updatedAtStr)
+	err := row.Scan(&id, &name, &email, &passwordHash, &role, &createdAtStr, &updatedAtStr)
 	err = data.SQLiteErrorComparator(err)
 
 	if err != nil {
@@ -119,6 +121,7 @@ func GetUser(ctx context.Context, db *sql.DB, req schemas.UserFetchRequest) (*en
 		Name:         name,
 		Email:        email,
 		PasswordHash: passwordHash,
+		Role:         role,
 
 		CreatedAt:     *createdAt,
 		LastUpdatedAt: *updatedAt,
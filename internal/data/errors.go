@@ -25,6 +25,9 @@ func SQLiteErrorComparator(err error) error {
 	switch sqliteErr.ExtendedCode {
 	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
 		return errs.NewError(err, errs.SQLErrorType, errs.ErrAlreadyExists)
+	case sqlite3.ErrConstraintForeignKey:
+		// The row refers to one that does not exist.
+		return errs.NewError(err, errs.SQLErrorType, errs.ErrNotFound)
 	default:
 		return err
 	}
@@ -0,0 +1,77 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+* Create the table of an entity, or bring an existing one up to date by adding
+* the columns it is missing. Columns added this way must be nullable or carry a
+* DEFAULT in their "cnstr" tag, as SQLite requires for ALTER TABLE ... ADD COLUMN.
+ */
+func MigrateTable[T interface{}](ctx context.Context, db *sql.DB, entity T) ([]string, error) {
+	stmt, err := CreateTable(ctx, db, entity)
+	if err != nil {
+		return []string{stmt}, err
+	}
+	stmts := []string{stmt}
+
+	name := strings.ToLower(reflect.TypeOf(entity).Name())
+
+	existing, err := TableColumns(ctx, db, name)
+	if err != nil {
+		return stmts, fmt.Errorf("[func MigrateTable] failed to inspect table %s :: %w", name, err)
+	}
+
+	for _, field := range ExtractFields(entity, false) {
+		if _, ok := existing[field.Name]; ok {
+			continue
+		}
+
+		stmt := PrepareAddColumnStmt(name, field)
+		stmts = append(stmts, stmt)
+
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return stmts, fmt.Errorf("[func MigrateTable] failed to add column %s.%s :: %w", name, field.Name, err)
+		}
+	}
+	return stmts, nil
+}
+
+func PrepareAddColumnStmt(table string, field SQLField) string {
+	stmt := fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN %s %s %s`, table, field.Name, field.Datatype, field.Constraints)
+	if field.Reference != "" {
+		stmt += " REFERENCES " + field.Reference
+	}
+	return strings.TrimSpace(stmt) + ";"
+}
+
+// Lowercased column names of a table.
+func TableColumns(ctx context.Context, db *sql.DB, table string) (map[string]struct{}, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info("%s");`, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols := make(map[string]struct{})
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			typ       string
+			notnull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notnull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		cols[strings.ToLower(name)] = struct{}{}
+	}
+	return cols, rows.Err()
+}
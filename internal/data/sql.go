@@ -19,6 +19,20 @@ type SQLEntity interface{}
 type SQLSelectArgs struct {
 	Where SQLWhereClause
 	Limit int // limit the amount of rows to fetch
+
+	Conditions []SQLCondition // ANDed with Where, for anything but equality
+	OrderBy    []SQLOrder
+}
+
+// Raw boolean expression with its placeholder arguments, e.g. {"timeMins >= ?", [30]}.
+type SQLCondition struct {
+	Expr string
+	Args []any
+}
+
+type SQLOrder struct {
+	Column string
+	Desc   bool
 }
 
 func PrepareCreateTableStmt(name string, fields []SQLField) string {
@@ -60,6 +74,47 @@ func PrepareWhereClause(clause SQLWhereClause) (string, []any, error) {
 	return where, values, nil
 }
 
+func PrepareSelectStmt(table string, columns []string, args SQLSelectArgs) (string, []any, error) {
+	stmt := fmt.Sprintf(`SELECT %s FROM "%s"`, strings.Join(columns, ", "), table)
+
+	parts := make([]string, 0, len(args.Conditions)+1)
+	values := make([]any, 0)
+
+	if args.Where.Where != nil && len(ExtractFields(args.Where.Where, true)) > 0 {
+		clause, whereVals, err := PrepareWhereClause(args.Where)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, "("+strings.TrimPrefix(clause, "WHERE ")+")")
+		values = append(values, whereVals...)
+	}
+	for _, c := range args.Conditions {
+		parts = append(parts, "("+c.Expr+")")
+		values = append(values, c.Args...)
+	}
+	if len(parts) > 0 {
+		stmt += " WHERE " + strings.Join(parts, " AND ")
+	}
+
+	if len(args.OrderBy) > 0 {
+		orders := make([]string, len(args.OrderBy))
+		for i, o := range args.OrderBy {
+			orders[i] = o.Column
+			if o.Desc {
+				orders[i] += " DESC"
+			}
+		}
+		stmt += " ORDER BY " + strings.Join(orders, ", ")
+	}
+
+	if args.Limit > 0 {
+		stmt += " LIMIT ?"
+		values = append(values, args.Limit)
+	}
+
+	return stmt, values, nil
+}
+
 func PrepareWhat(fields []SQLField) string {
 	what := make([]string, len(fields))
 	for i, v := range fields {
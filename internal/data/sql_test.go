@@ -75,6 +75,39 @@ func TestPrepareStatement(t *testing.T) {
 	}
 }
 
+func TestPrepareSelectStmt(t *testing.T) {
+	stmt, vals, err := data.PrepareSelectStmt("testuserentity", []string{"id", "name"}, data.SQLSelectArgs{
+		Where: data.SQLWhereClause{
+			Where: TestUserEntity{Name: "ashton"},
+		},
+		Conditions: []data.SQLCondition{
+			{Expr: "id > ?", Args: []any{"a"}},
+		},
+		OrderBy: []data.SQLOrder{{Column: "id", Desc: true}},
+		Limit:   10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("Generated SQL: %s", stmt)
+
+	expected := `SELECT id, name FROM "testuserentity" WHERE (name=?) AND (id > ?) ORDER BY id DESC LIMIT ?`
+	if stmt != expected {
+		t.Fatalf("expected %q, got %q", expected, stmt)
+	}
+	if len(vals) != 3 || vals[0] != "ashton" || vals[1] != "a" || vals[2] != 10 {
+		t.Fatalf("unexpected values %v", vals)
+	}
+
+	stmt, vals, err = data.PrepareSelectStmt("testuserentity", []string{"id"}, data.SQLSelectArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != `SELECT id FROM "testuserentity"` || len(vals) != 0 {
+		t.Fatalf("unexpected statement %q with values %v", stmt, vals)
+	}
+}
+
 func TestCreateTable(t *testing.T) {
 	db, err := createTestDB()
 	if err != nil {
@@ -265,7 +298,7 @@ func TestDelete(t *testing.T) {
 
 	res, err := data.Delete(ctx, db, data.SQLWhereClause{
 		Where: TestUserEntity{
-			ID: usedID,
+			ID:   usedID,
 			Name: usedName,
 		},
 	})
@@ -281,4 +314,52 @@ func TestDelete(t *testing.T) {
 	if err != sql.ErrNoRows {
 		t.Fatalf("Found {[ID : %s] and [Name : %s]} - expected nothing", id, name)
 	}
-}
\ No newline at end of file
+}
+
+type TestMigrationEntity struct {
+	ID   string `type:"TEXT" cnstr:"PRIMARY KEY"`
+	Name string `type:"TEXT" cnstr:"NOT NULL DEFAULT 'unnamed'"`
+}
+
+func TestMigrateTable(t *testing.T) {
+	db, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	defer cancel()
+
+	// An outdated version of the table, without the "name" column.
+	_, err = db.ExecContext(ctx, `CREATE TABLE testmigrationentity (id TEXT PRIMARY KEY);`)
+	if err != nil {
+		t.Fatalf("failed to create outdated table :: %v", err)
+	}
+	defer dropTestTable(db, "testmigrationentity")
+
+	usedID := uuid.NewString()
+	if _, err := db.Exec("INSERT INTO testmigrationentity(id) VALUES(?);", usedID); err != nil {
+		t.Fatalf("failed to insert data :: %v", err)
+	}
+
+	stmts, err := data.MigrateTable(ctx, db, TestMigrationEntity{})
+	t.Logf("Executed :: %v", stmts)
+	if err != nil {
+		t.Fatalf("migration failed :: %v", err)
+	}
+
+	// Running it again must be a no-op.
+	if _, err := data.MigrateTable(ctx, db, TestMigrationEntity{}); err != nil {
+		t.Fatalf("migrating an up to date table failed :: %v", err)
+	}
+
+	var name string
+	err = db.QueryRow("SELECT name FROM testmigrationentity WHERE id = ?", usedID).Scan(&name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "unnamed" {
+		t.Fatalf("data mismatch : expected [Name : unnamed], got [Name : %s]", name)
+	}
+}
@@ -12,7 +12,7 @@ type SQLite struct {
 }
 
 func NewSQLite(ctx context.Context) (*SQLite, error) {
-	db, err := sql.Open("sqlite3", filepath.Join(".data", "mocker.db"))
+	db, err := OpenSQLite(filepath.Join(".data", "mocker.db"))
 	if err != nil {
 		return nil, fmt.Errorf("[func NewSQLite] has failed :: %w", err)
 	}
@@ -20,4 +20,10 @@ func NewSQLite(ctx context.Context) (*SQLite, error) {
 	return &SQLite{
 		DB: db,
 	}, nil 
-}	
\ No newline at end of file
+}
+
+// Open the database at path with foreign keys enforced, so that deletes cascade
+// as the "ref" tags of the entities declare.
+func OpenSQLite(path string) (*sql.DB, error) {
+	return sql.Open("sqlite3", path+"?_foreign_keys=on")
+}
@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// Represent the "attempt" table.
+// An attempt is the durable record of a submitted session.
+type Attempt struct {
+	ID          string    `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
+	SessionID   string    `type:"TEXT" cnstr:"UNIQUE NOT NULL" json:"session_id"`
+	MockID      string    `type:"TEXT" cnstr:"NOT NULL" ref:"Mock(ID) ON DELETE CASCADE" json:"mock_id"`
+	UserID      string    `type:"TEXT" cnstr:"NOT NULL" ref:"User(ID)" json:"user_id"`
+	TotalMarks  float64   `type:"REAL" cnstr:"NOT NULL" json:"total_marks"`
+	MaxMarks    int       `type:"NUMBER" cnstr:"NOT NULL" json:"max_marks"`
+	StartedAt   time.Time `type:"TEXT" cnstr:"NOT NULL" json:"started_at"`
+	SubmittedAt time.Time `type:"TEXT" cnstr:"NOT NULL" json:"submitted_at"`
+}
+
+// Per-question breakdown of an attempt.
+// QuestionID is deliberately not a foreign key, the attempt outlives edits to the mock.
+type AttemptAnswer struct {
+	ID               string  `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
+	AttemptID        string  `type:"TEXT" cnstr:"NOT NULL" ref:"Attempt(ID) ON DELETE CASCADE" json:"attempt_id"`
+	QuestionID       string  `type:"TEXT" cnstr:"NOT NULL" json:"question_id"`
+	SelectedOptionID string  `type:"TEXT" json:"selected_option_id"`                   // Set for single-option answers only.
+	Response         string  `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"response"` // JSON array, see session.Response.
+	IsCorrect        bool    `type:"NUMBER" cnstr:"NOT NULL" json:"is_correct"`
+	Points           float64 `type:"REAL" cnstr:"NOT NULL" json:"points"`
+}
@@ -0,0 +1,52 @@
+package entities
+
+import "time"
+
+// A question kept in its author's bank, shared by every mock that links it
+// through MockBankQuestion. Points is the default a link starts from.
+type BankQuestion struct {
+	ID              string    `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
+	AuthorID        string    `type:"TEXT" cnstr:"NOT NULL" ref:"User(ID)" json:"author_id"`
+	Type            string    `type:"TEXT" cnstr:"NOT NULL DEFAULT 'single_choice'" json:"type"`
+	Problem         string    `type:"TEXT" cnstr:"NOT NULL" json:"problem"`
+	Points          int       `type:"NUMBER" cnstr:"NOT NULL" json:"points"`
+	CorrectOptionID string    `type:"TEXT" cnstr:"NOT NULL" json:"correct_option_id,omitempty"`
+	Explanation     string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"explanation,omitempty"`
+	NegativePoints  *float64  `type:"REAL" json:"negative_points,omitempty"`
+	NumericAnswer   *float64  `type:"REAL" json:"numeric_answer,omitempty"`
+	Tolerance       float64   `type:"REAL" cnstr:"NOT NULL DEFAULT 0" json:"tolerance,omitempty"`
+	CreatedAt       time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
+	LastUpdatedAt   time.Time `type:"TEXT" cnstr:"NOT NULL" json:"last_updated_at"`
+}
+
+// See MockQuestion.QuestionType.
+func (q BankQuestion) QuestionType() string {
+	if q.Type == "" {
+		return QuestionSingleChoice
+	}
+	return q.Type
+}
+
+// Same shape as MockOption, so the two convert into one another.
+type BankOption struct {
+	ID            string    `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
+	Number        int       `type:"NUMBER" cnstr:"NOT NULL" json:"number"`
+	Option        string    `type:"TEXT" cnstr:"NOT NULL" json:"option"`
+	IsCorrect     bool      `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"is_correct,omitempty"`
+	Match         string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"match,omitempty"`
+	MatchID       string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"match_id,omitempty"`
+	QuestionID    string    `type:"TEXT" cnstr:"NOT NULL" ref:"BankQuestion(ID) ON DELETE CASCADE" json:"question_id"`
+	CreatedAt     time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
+	LastUpdatedAt time.Time `type:"TEXT" cnstr:"NOT NULL" json:"last_updated_at"`
+}
+
+// A bank question used by a mock, with the points it is worth and its place there.
+// Positions share one sequence with the mock's own questions.
+type MockBankQuestion struct {
+	MockID     string    `type:"TEXT" cnstr:"NOT NULL" ref:"Mock(ID) ON DELETE CASCADE" json:"mock_id"`
+	QuestionID string    `type:"TEXT" cnstr:"NOT NULL" ref:"BankQuestion(ID)" json:"question_id"`
+	Points     int       `type:"NUMBER" cnstr:"NOT NULL" json:"points"`
+	Position   int       `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"position"`
+	Pool       string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"pool,omitempty"`
+	CreatedAt  time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
+}
@@ -2,32 +2,144 @@ package entities
 
 import "time"
 
+// Whether candidates see correct answers and explanations once they submit.
+const (
+	ReviewNever       = "never"
+	ReviewAfterSubmit = "after_submit"
+)
+
+// Lifecycle of a mock, see mock.SetStatus for the allowed transitions.
+const (
+	MockDraft     = "draft"     // Being written, visible to its author only.
+	MockPublished = "published" // Open to candidates.
+	MockClosed    = "closed"    // Visible, but no new sessions.
+	MockArchived  = "archived"  // Hidden, attempts are kept.
+)
+
+// Who may open a mock, see mock.CheckAccess. Invited users may open any of them.
+const (
+	VisibilityPublic     = "public"      // Listed, open to everyone.
+	VisibilityUnlisted   = "unlisted"    // Open to whoever has the ID, not listed or searchable.
+	VisibilityInviteOnly = "invite_only" // Invited users only.
+	VisibilityCode       = "code"        // Whoever has the access code.
+)
+
+// Question types, see session.PolicyScorer for how each is graded.
+const (
+	QuestionSingleChoice   = "single_choice"   // One correct option.
+	QuestionMultipleSelect = "multiple_select" // Any number of options flagged IsCorrect.
+	QuestionTrueFalse      = "true_false"      // Two options, one correct.
+	QuestionNumeric        = "numeric"         // No options, NumericAnswer within Tolerance.
+	QuestionOrdering       = "ordering"        // Options put back in the order of their Number.
+	QuestionMatching       = "matching"        // Each option paired with its own Match.
+)
+
 // Represent the "mock" table.
 type Mock struct {
-	ID             string    `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
-	Topic          string    `type:"TEXT" cnstr:"NOT NULL" json:"topic"`
-	Instructions   string    `type:"TEXT" json:"instructions"`
-	TimeMins       int       `type:"NUMBER" cnstr:"NOT NULL" json:"time_mins"`
-	AuthorID       string    `type:"TEXT" cnstr:"NOT NULL" ref:"User(ID)" json:"author_id"`
-	CreatedAt      time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
-	LastUpdatedAt  time.Time `type:"TEXT" cnstr:"NOT NULL" json:"last_updated_at"`
+	ID           string `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
+	Topic        string `type:"TEXT" cnstr:"NOT NULL" json:"topic"`
+	Instructions string `type:"TEXT" json:"instructions"`
+	TimeMins     int    `type:"NUMBER" cnstr:"NOT NULL" json:"time_mins"`
+	AuthorID     string `type:"TEXT" cnstr:"NOT NULL" ref:"User(ID)" json:"author_id"`
+	ReviewPolicy string `type:"TEXT" cnstr:"NOT NULL DEFAULT 'never'" json:"review_policy"`
+
+	// Scoring policy, see session.PolicyScorer.
+	NegativeMarking float64 `type:"REAL" cnstr:"NOT NULL DEFAULT 1" json:"negative_marking"`
+	FloorAtZero     bool    `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"floor_at_zero"`
+	PartialCredit   bool    `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"partial_credit"`
+
+	// Mocks written before statuses existed were usable right away, hence the default.
+	Status string `type:"TEXT" cnstr:"NOT NULL DEFAULT 'published'" json:"status"`
+
+	Visibility     string `type:"TEXT" cnstr:"NOT NULL DEFAULT 'public'" json:"visibility"`
+	AccessCodeHash string `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"-"` // bcrypt, never leaves the database.
+
+	// Each session gets its own order of questions, and of options within them, see session.Shuffle.
+	ShuffleQuestions bool `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"shuffle_questions"`
+	ShuffleOptions   bool `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"shuffle_options"`
+
+	// Per-user attempt allowance, zero meaning unlimited, and the wait between two attempts.
+	MaxAttempts  int `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"max_attempts"`
+	CooldownMins int `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"cooldown_mins"`
+
+	// Sessions may only start within the window and end by ClosesAt. Either side may be open.
+	// Timezone is the IANA zone the author scheduled in, kept for display.
+	OpensAt  *time.Time `type:"TEXT" json:"opens_at,omitempty"`
+	ClosesAt *time.Time `type:"TEXT" json:"closes_at,omitempty"`
+	Timezone string     `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"timezone,omitempty"`
+
+	// Archived mocks are hidden and cannot be started, but their attempts are kept.
+	ArchivedAt *time.Time `type:"TEXT" json:"archived_at,omitempty"`
+
+	CreatedAt     time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
+	LastUpdatedAt time.Time `type:"TEXT" cnstr:"NOT NULL" json:"last_updated_at"`
 }
 
 type MockQuestion struct {
 	ID              string    `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
+	Type            string    `type:"TEXT" cnstr:"NOT NULL DEFAULT 'single_choice'" json:"type"`
 	Problem         string    `type:"TEXT" cnstr:"NOT NULL" json:"problem"`
 	Points          int       `type:"NUMBER" cnstr:"NOT NULL" json:"points"`
-	CorrectOptionID string    `type:"TEXT" cnstr:"NOT NULL" ref:"MockOption(ID)" json:"correct_option_id,omitempty"`
-	MockID          string    `type:"TEXT" cnstr:"NOT NULL" ref:"Mock(ID)" json:"mock_id"`
+	CorrectOptionID string    `type:"TEXT" cnstr:"NOT NULL" json:"correct_option_id,omitempty"`
+	Explanation     string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"explanation,omitempty"`
+	NegativePoints  *float64  `type:"REAL" json:"negative_points,omitempty"` // Overrides the mock's negative marking when set.
+	NumericAnswer   *float64  `type:"REAL" json:"numeric_answer,omitempty"`
+	Tolerance       float64   `type:"REAL" cnstr:"NOT NULL DEFAULT 0" json:"tolerance,omitempty"`
+	Position        int       `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"position"`
+	Pool            string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"pool,omitempty"` // Name of a MockPool, empty when always asked.
+	MockID          string    `type:"TEXT" cnstr:"NOT NULL" ref:"Mock(ID) ON DELETE CASCADE" json:"mock_id"`
 	CreatedAt       time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
 	LastUpdatedAt   time.Time `type:"TEXT" cnstr:"NOT NULL" json:"last_updated_at"`
 }
 
+// Whether sessions may start at the given moment, as far as the window goes.
+func (m Mock) OpenAt(t time.Time) bool {
+	if m.OpensAt != nil && t.Before(*m.OpensAt) {
+		return false
+	}
+	if m.ClosesAt != nil && !t.Before(*m.ClosesAt) {
+		return false
+	}
+	return true
+}
+
+// Empty for rows written before question types existed.
+func (q MockQuestion) QuestionType() string {
+	if q.Type == "" {
+		return QuestionSingleChoice
+	}
+	return q.Type
+}
+
 type MockOption struct {
 	ID            string    `type:"TEXT" cnstr:"PRIMARY KEY" json:"id"`
 	Number        int       `type:"NUMBER" cnstr:"NOT NULL" json:"number"`
 	Option        string    `type:"TEXT" cnstr:"NOT NULL" json:"option"`
-	QuestionID    string    `type:"TEXT" cnstr:"NOT NULL" ref:"MockQuestion(ID)" json:"question_id"`
+	IsCorrect     bool      `type:"NUMBER" cnstr:"NOT NULL DEFAULT 0" json:"is_correct,omitempty"`
+	Match         string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"match,omitempty"`    // Right-hand side of a matching pair.
+	MatchID       string    `type:"TEXT" cnstr:"NOT NULL DEFAULT ''" json:"match_id,omitempty"` // Lets candidates pick the right-hand side without learning its option.
+	QuestionID    string    `type:"TEXT" cnstr:"NOT NULL" ref:"MockQuestion(ID) ON DELETE CASCADE" json:"question_id"`
 	CreatedAt     time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
 	LastUpdatedAt time.Time `type:"TEXT" cnstr:"NOT NULL" json:"last_updated_at"`
-}
\ No newline at end of file
+}
+
+// A user allowed into a mock regardless of its visibility.
+type MockInvitation struct {
+	MockID    string    `type:"TEXT" cnstr:"NOT NULL" ref:"Mock(ID) ON DELETE CASCADE" json:"mock_id"`
+	UserID    string    `type:"TEXT" cnstr:"NOT NULL" ref:"User(ID)" json:"user_id"`
+	CreatedAt time.Time `type:"TEXT" cnstr:"NOT NULL" json:"created_at"`
+}
+
+// Questions of a mock sharing a Pool name, of which each session draws Draw at random.
+// Questions outside any pool are asked in every session.
+type MockPool struct {
+	MockID string `type:"TEXT" cnstr:"NOT NULL" ref:"Mock(ID) ON DELETE CASCADE" json:"mock_id"`
+	Name   string `type:"TEXT" cnstr:"NOT NULL" json:"name"`
+	Draw   int    `type:"NUMBER" cnstr:"NOT NULL" json:"draw"`
+}
+
+// Free-form labels used to filter mocks, unique per mock.
+type MockTag struct {
+	MockID string `type:"TEXT" cnstr:"NOT NULL" ref:"Mock(ID) ON DELETE CASCADE" json:"mock_id"`
+	Tag    string `type:"TEXT" cnstr:"NOT NULL" json:"tag"`
+}
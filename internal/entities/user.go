@@ -2,12 +2,22 @@ package entities
 
 import "time"
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID           string `type:"TEXT" cnstr:"PRIMARY KEY"`
 	Name         string `type:"VARCHAR(45)" cnstr:"NOT NULL"`
 	Email        string `type:"TEXT" cnstr:"UNIQUE NOT NULL"`
 	PasswordHash string `type:"TEXT" cnstr:"NOT NULL"`
+	Role         string `type:"TEXT" cnstr:"NOT NULL DEFAULT 'user'"`
 	
 	CreatedAt time.Time `type:"TEXT" cnstr:"NOT NULL"`
 	LastUpdatedAt time.Time `type:"TEXT" cnstr:"NOT NULL"`
-}
\ No newline at end of file
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
@@ -11,6 +11,10 @@ const (
 	ErrDataIllegal  // Data that violates the schema.
 	ErrInternalFailure
 	ErrUndefined 	// Errors that have not been explicitly defined in this codebase.
+	ErrExpired      // Time-bound data (e.g. a session) past its deadline.
+	ErrForbidden    // The caller may not read or modify the data.
+	ErrConflict     // The operation conflicts with the current state of the data.
+	ErrLimitReached // A per-user allowance, e.g. attempts at a mock, is used up for now.
 )
 
 type ErrorType int
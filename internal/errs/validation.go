@@ -48,3 +48,8 @@ func Validate(data interface{}) (error) {
 	newE := NewError(errors.Join(errs...), DataErrorType, ErrDataIllegal)
 	return newE
 }
+
+// Register a struct-level check for rules that span several fields.
+func RegisterStructValidation(fn validator.StructLevelFunc, types ...interface{}) {
+	validate.RegisterStructValidation(fn, types...)
+}
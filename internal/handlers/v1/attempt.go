@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/auth"
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/logging"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/supervisor"
+	"github.com/gofiber/fiber/v2"
+)
+
+const ATTEMPT_TIMEOUT = 40 * time.Second
+
+// assert: AttemptHandler implements Handler interface.
+var _ Handler = (*AttemptHandler)(nil)
+
+type AttemptHandler struct {
+	Supervisor *supervisor.Supervisor
+	SQLite     *data.SQLite
+}
+
+func NewAttemptHandler(su *supervisor.Supervisor) *AttemptHandler {
+	return &AttemptHandler{
+		Supervisor: su,
+		SQLite:     su.SQLite,
+	}
+}
+
+func (h *AttemptHandler) MapRoutes(router *fiber.Group) {
+	router.Get("/", h.handleList)
+	router.Get("/:id", h.handleGET)
+	router.Get("/:id/results", h.handleResults)
+}
+
+// List the current user's attempts.
+// Authors may pass ?mock_id= to list every candidate's attempts on their mock.
+func (h *AttemptHandler) handleList(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ATTEMPT_TIMEOUT)
+	defer cancel()
+
+	userID := user.ID
+	mockID := c.Query("mock_id")
+
+	if mockID != "" {
+		mck, err := mock.GetMockMeta(ctx, h.SQLite.DB, mockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		if mck.AuthorID == user.ID {
+			userID = ""
+		}
+	}
+
+	attempts, err := attempt.ListAttempts(ctx, h.SQLite.DB, userID, mockID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, attempts, ""))
+}
+
+// Fetch one attempt with its per-question breakdown.
+// Visible to the candidate who made it and to the author of the mock.
+func (h *AttemptHandler) handleGET(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	attemptID := c.Params("id")
+	if attemptID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errs.GenericBadRequstErr("id"), "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ATTEMPT_TIMEOUT)
+	defer cancel()
+
+	att, err := attempt.GetAttempt(ctx, h.SQLite.DB, attemptID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if att.UserID != user.ID {
+		mck, err := mock.GetMockMeta(ctx, h.SQLite.DB, att.MockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		if mck.AuthorID != user.ID {
+			return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(errors.New("attempt belongs to another user"), "Forbidden"))
+		}
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, att, ""))
+}
+
+// Per-question results, with correct answers revealed according to the mock's review policy.
+func (h *AttemptHandler) handleResults(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	attemptID := c.Params("id")
+	if attemptID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errs.GenericBadRequstErr("id"), "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ATTEMPT_TIMEOUT)
+	defer cancel()
+
+	results, err := h.Supervisor.SessionManager.GetAnswerResults(ctx, attemptID, user.ID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, results, ""))
+}
+
+func (h *AttemptHandler) handleError(c *fiber.Ctx, err error) error {
+	var e errs.Error
+	if errors.As(err, &e) {
+		logging.Log(slog.LevelError, c, "Attempt lookup failed", "error", e)
+
+		switch e.Code {
+		case errs.ErrNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(schemas.NewErrorAPIResponse(err, "Not found"))
+		case errs.ErrForbidden:
+			return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Forbidden"))
+		case errs.ErrInternalFailure:
+			return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal failure"))
+		}
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Unknown error"))
+}
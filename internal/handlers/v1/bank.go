@@ -0,0 +1,215 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/ashtonx86/mocker/internal/auth"
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/logging"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/supervisor"
+	"github.com/gofiber/fiber/v2"
+)
+
+// assert: BankHandler implements Handler interface.
+var _ Handler = (*BankHandler)(nil)
+
+// Questions in the current user's bank, linked into mocks through their bank_questions.
+type BankHandler struct {
+	Supervisor *supervisor.Supervisor
+	SQLite     *data.SQLite
+}
+
+func NewBankHandler(su *supervisor.Supervisor) *BankHandler {
+	return &BankHandler{
+		Supervisor: su,
+		SQLite:     su.SQLite,
+	}
+}
+
+func (h *BankHandler) MapRoutes(router *fiber.Group) {
+	router.Post("/", h.handlePOST)
+	router.Get("/", h.handleList)
+	router.Get("/:id", h.handleGET)
+	router.Put("/:id", h.handleUpdate)
+	router.Delete("/:id", h.handleDelete)
+}
+
+func (h *BankHandler) handlePOST(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	req := new(schemas.MockQuestionSchema)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	if err := errs.Validate(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	question, err := mock.CreateBankQuestion(ctx, h.SQLite.DB, user.ID, *req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(schemas.NewAPIResponse(true, question, ""))
+}
+
+// List the current user's bank.
+func (h *BankHandler) handleList(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	questions, err := mock.ListBankQuestions(ctx, h.SQLite.DB, user.ID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, questions, ""))
+}
+
+func (h *BankHandler) handleGET(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	questionID := c.Params("id")
+	if questionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing question ID"), "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	question, err := mock.GetBankQuestion(ctx, h.SQLite.DB, questionID, user)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, question, ""))
+}
+
+// Update a bank question and with it every mock linking it. The body is the complete question,
+// options without an ID are added and those left out are removed.
+// Structural changes are refused while sessions for any of those mocks are active,
+// and no session starts on them while the update holds their edit locks.
+func (h *BankHandler) handleUpdate(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	questionID := c.Params("id")
+	if questionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing question ID"), "Bad request"))
+	}
+
+	req := new(schemas.MockQuestionUpdateSchema)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	if err := errs.Validate(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	current, err := mock.GetBankQuestion(ctx, h.SQLite.DB, questionID, user)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	if current.AuthorID != user.ID {
+		return h.handleError(c, mock.ErrNotBankAuthor)
+	}
+
+	// Mocks linking the question later are refused by UpdateBankQuestion, see UpdateOptions.
+	unlock, err := h.Supervisor.SessionManager.LockMocks(ctx, current.MockIDs...)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	defer unlock()
+
+	active := 0
+	for _, mockID := range current.MockIDs {
+		n, err := h.Supervisor.SessionManager.ActiveSessions(ctx, mockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		active += n
+	}
+
+	opts := mock.UpdateOptions{AllowStructural: active == 0, LinkedMocks: current.MockIDs}
+	question, err := mock.UpdateBankQuestion(ctx, h.SQLite.DB, questionID, user.ID, *req, opts)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	for _, mockID := range question.MockIDs {
+		if err := h.Supervisor.MockCache.Invalidate(ctx, mockID); err != nil {
+			logging.Log(slog.LevelWarn, c, "Mock cache invalidation failed", "mock_id", mockID, "error", err)
+		}
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, question, ""))
+}
+
+// Remove a question from the bank. Questions still linked by a mock are refused.
+func (h *BankHandler) handleDelete(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	questionID := c.Params("id")
+	if questionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing question ID"), "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	if err := mock.DeleteBankQuestion(ctx, h.SQLite.DB, questionID, user); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, nil, ""))
+}
+
+func (h *BankHandler) handleError(c *fiber.Ctx, err error) error {
+	var e errs.Error
+	if errors.As(err, &e) {
+		logging.Log(slog.LevelError, c, "Bank operation failed", "error", e)
+
+		switch e.Code {
+		case errs.ErrNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(schemas.NewErrorAPIResponse(err, "Not found"))
+		case errs.ErrDataIllegal:
+			return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+		case errs.ErrForbidden:
+			return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Forbidden"))
+		case errs.ErrConflict:
+			return c.Status(fiber.StatusConflict).JSON(schemas.NewErrorAPIResponse(err, "Conflict"))
+		case errs.ErrInternalFailure:
+			return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal failure"))
+		}
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Unknown error"))
+}
@@ -16,4 +16,13 @@ func MapV1Handlers(app *fiber.App, su *supervisor.Supervisor) {
 
 	sessionHandler := NewSessionHandler(su)
 	sessionHandler.MapRoutes(router.Group("/session").(*fiber.Group))
+
+	attemptHandler := NewAttemptHandler(su)
+	attemptHandler.MapRoutes(router.Group("/attempt").(*fiber.Group))
+
+	searchHandler := NewSearchHandler(su)
+	searchHandler.MapRoutes(router.Group("/search").(*fiber.Group))
+
+	bankHandler := NewBankHandler(su)
+	bankHandler.MapRoutes(router.Group("/bank").(*fiber.Group))
 }
\ No newline at end of file
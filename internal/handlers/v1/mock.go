@@ -6,8 +6,10 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/ashtonx86/mocker/internal/attempt"
 	"github.com/ashtonx86/mocker/internal/auth"
 	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
 	"github.com/ashtonx86/mocker/internal/errs"
 	"github.com/ashtonx86/mocker/internal/logging"
 	"github.com/ashtonx86/mocker/internal/mock"
@@ -35,7 +37,16 @@ func NewMockHandler(su *supervisor.Supervisor) *MockHandler {
 
 func (h *MockHandler) MapRoutes(router *fiber.Group) {
 	router.Post("/", h.handlePOST) 
+	router.Get("/", h.handleList)
 	router.Get("/:id", h.handleGET)
+	router.Put("/:id", h.handleUpdate)
+	router.Patch("/:id", h.handleUpdate)
+	router.Delete("/:id", h.handleDelete)
+	router.Post("/:id/publish", h.handleStatus(entities.MockPublished))
+	router.Post("/:id/close", h.handleStatus(entities.MockClosed))
+	router.Get("/:id/invitations", h.handleListInvitations)
+	router.Post("/:id/invitations", h.handleInvite)
+	router.Delete("/:id/invitations/:userID", h.handleUninvite)
 }
 
 func (h *MockHandler) handlePOST(c *fiber.Ctx) error {
@@ -67,6 +78,8 @@ func (h *MockHandler) handlePOST(c *fiber.Ctx) error {
 			switch e.Code {
 			case errs.ErrAlreadyExists:
 				return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Already exists"))
+			case errs.ErrDataIllegal:
+				return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
 			case errs.ErrDataMismatch:
 				return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Data mismatch"))
 			case errs.ErrInternalFailure:
@@ -79,6 +92,37 @@ func (h *MockHandler) handlePOST(c *fiber.Ctx) error {
 	return c.JSON(schemas.NewAPIResponse(true, entity, ""))
 }
 
+// List mocks with cursor pagination. Pass the returned next_cursor as ?cursor= to fetch the next page.
+func (h *MockHandler) handleList(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	req := new(schemas.MockListRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	if err := errs.Validate(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	req.ViewerID = user.ID
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	page, err := mock.ListMocks(ctx, h.SQLite.DB, *req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, page, ""))
+}
+
+// Fetch a mock. Only its author or an admin see the answer key, see mock.FullMock.VisibleTo.
+// Mocks protected by an access code take it as ?code=.
 func (h *MockHandler) handleGET(c *fiber.Ctx) error {
 	mockID := c.Params("id")
 	if mockID == "" {
@@ -88,7 +132,7 @@ func (h *MockHandler) handleGET(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
 	defer cancel()
 
-	entity, err := mock.GetMock(ctx, h.SQLite.DB, mockID)
+	entity, err := h.Supervisor.MockCache.Get(ctx, mockID)
 	if err != nil {
 		var e errs.Error
 		if errors.As(err, &e) {
@@ -104,5 +148,293 @@ func (h *MockHandler) handleGET(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Unknown error"))
 	}
 
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	// Drafts and archived mocks stay visible to whoever may still manage them.
+	if !mock.CanDelete(&entity.Mock, user) {
+		switch entity.Status {
+		case entities.MockArchived:
+			return c.Status(fiber.StatusNotFound).JSON(schemas.NewErrorAPIResponse(mock.ErrMockArchived, "Not found"))
+		case entities.MockDraft:
+			return c.Status(fiber.StatusNotFound).JSON(schemas.NewErrorAPIResponse(errors.New("mock not found"), "Not found"))
+		}
+	}
+
+	var access mock.Access
+	if !mock.CanDelete(&entity.Mock, user) {
+		ses, err := h.Supervisor.SessionManager.Find(ctx, user.ID, mockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		if ses != nil {
+			access.InSession = true
+			access.Order = ses.Order()
+		}
+
+		attempts, err := attempt.ListAttempts(ctx, h.SQLite.DB, user.ID, mockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		access.Submitted = len(attempts) > 0
+		if access.Submitted {
+			access.Attempted, err = attempt.AttemptedQuestionIDs(ctx, h.SQLite.DB, user.ID, mockID)
+			if err != nil {
+				return h.handleError(c, err)
+			}
+		}
+
+		// Candidates who already got in keep seeing the mock without the code.
+		if !access.InSession && !access.Submitted {
+			if err := mock.CheckAccess(ctx, h.SQLite.DB, &entity.Mock, user.ID, c.Query("code")); err != nil {
+				return h.handleError(c, err)
+			}
+		}
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, entity.VisibleTo(user, access), ""))
+}
+
+// Update a mock. PUT replaces the mock and must carry every field, PATCH only changes the fields it carries.
+// Structural changes are refused while sessions for the mock are active,
+// and no session starts while the update holds the mock's edit lock.
+func (h *MockHandler) handleUpdate(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	mockID := c.Params("id")
+	if mockID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing mock ID"), "Bad request"))
+	}
+
+	req := new(schemas.MockUpdateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	if err := errs.Validate(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	if c.Method() == fiber.MethodPut && (req.Topic == nil || req.Instructions == nil || req.TimeMins == nil || req.Questions == nil) {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("PUT requires topic, instructions, time_mins and questions"), "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	// Checked before anything about the mock's sessions is disclosed.
+	meta, err := mock.GetMockMeta(ctx, h.SQLite.DB, mockID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	if meta.AuthorID != user.ID {
+		return h.handleError(c, mock.ErrNotMockAuthor)
+	}
+
+	unlock, err := h.Supervisor.SessionManager.LockMocks(ctx, mockID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	defer unlock()
+
+	active, err := h.Supervisor.SessionManager.ActiveSessions(ctx, mockID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	entity, err := mock.UpdateMock(ctx, h.SQLite.DB, mockID, user.ID, *req, mock.UpdateOptions{AllowStructural: active == 0})
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	h.invalidate(ctx, c, mockID)
+
 	return c.JSON(schemas.NewAPIResponse(true, entity, ""))
 }
+
+// Move a mock to the given status, see mock.SetStatus.
+func (h *MockHandler) handleStatus(status string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := auth.GetCurrentUser(c)
+		if user == nil {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+
+		mockID := c.Params("id")
+		if mockID == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing mock ID"), "Bad request"))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+		defer cancel()
+
+		entity, err := mock.SetStatus(ctx, h.SQLite.DB, mockID, user, status)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		h.invalidate(ctx, c, mockID)
+
+		return c.JSON(schemas.NewAPIResponse(true, entity, ""))
+	}
+}
+
+// List the users invited to a mock.
+func (h *MockHandler) handleListInvitations(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	mockID := c.Params("id")
+	if mockID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing mock ID"), "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	invitations, err := mock.ListInvitations(ctx, h.SQLite.DB, mockID, user)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, invitations, ""))
+}
+
+// Invite users to a mock. Invited users may open it whatever its visibility.
+func (h *MockHandler) handleInvite(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	mockID := c.Params("id")
+	if mockID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing mock ID"), "Bad request"))
+	}
+
+	req := new(schemas.MockInvitationRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	if err := errs.Validate(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	if err := mock.Invite(ctx, h.SQLite.DB, mockID, user, req.UserIDs); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(schemas.NewAPIResponse(true, nil, ""))
+}
+
+// Withdraw a user's invitation. Sessions already started are left to finish.
+func (h *MockHandler) handleUninvite(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	mockID := c.Params("id")
+	userID := c.Params("userID")
+	if mockID == "" || userID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing mock or user ID"), "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	if err := mock.Uninvite(ctx, h.SQLite.DB, mockID, user, userID); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, nil, ""))
+}
+
+// Archive a mock, or delete it with everything that depends on it when ?hard=true.
+// Hard deletion is refused while sessions for the mock are active,
+// and no session starts while it holds the mock's edit lock.
+func (h *MockHandler) handleDelete(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	mockID := c.Params("id")
+	if mockID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errors.New("missing mock ID"), "Bad request"))
+	}
+
+	opts := mock.DeleteOptions{Hard: c.QueryBool("hard")}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MOCK_TIMEOUT)
+	defer cancel()
+
+	if opts.Hard {
+		// Checked before anything about the mock's sessions is disclosed.
+		meta, err := mock.GetMockMeta(ctx, h.SQLite.DB, mockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		if !mock.CanDelete(meta, user) {
+			return h.handleError(c, mock.ErrNotMockDeletable)
+		}
+
+		unlock, err := h.Supervisor.SessionManager.LockMocks(ctx, mockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		defer unlock()
+
+		active, err := h.Supervisor.SessionManager.ActiveSessions(ctx, mockID)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		if active > 0 {
+			return c.Status(fiber.StatusConflict).JSON(schemas.NewErrorAPIResponse(errors.New("sessions for this mock are still active"), "Conflict"))
+		}
+	}
+
+	if err := mock.DeleteMock(ctx, h.SQLite.DB, mockID, user, opts); err != nil {
+		return h.handleError(c, err)
+	}
+	h.invalidate(ctx, c, mockID)
+
+	return c.JSON(schemas.NewAPIResponse(true, nil, ""))
+}
+
+// The change is already committed, other instances catch up when their local copy expires.
+func (h *MockHandler) invalidate(ctx context.Context, c *fiber.Ctx, mockID string) {
+	if err := h.Supervisor.MockCache.Invalidate(ctx, mockID); err != nil {
+		logging.Log(slog.LevelWarn, c, "Mock cache invalidation failed", "mock_id", mockID, "error", err)
+	}
+}
+
+func (h *MockHandler) handleError(c *fiber.Ctx, err error) error {
+	var e errs.Error
+	if errors.As(err, &e) {
+		logging.Log(slog.LevelError, c, "Mock operation failed", "error", e)
+
+		switch e.Code {
+		case errs.ErrNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(schemas.NewErrorAPIResponse(err, "Not found"))
+		case errs.ErrDataIllegal:
+			return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+		case errs.ErrForbidden:
+			return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Forbidden"))
+		case errs.ErrConflict:
+			return c.Status(fiber.StatusConflict).JSON(schemas.NewErrorAPIResponse(err, "Conflict"))
+		case errs.ErrInternalFailure:
+			return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal failure"))
+		}
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Unknown error"))
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/auth"
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/logging"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/search"
+	"github.com/ashtonx86/mocker/internal/supervisor"
+	"github.com/gofiber/fiber/v2"
+)
+
+const SEARCH_TIMEOUT = 10 * time.Second
+
+// assert: SearchHandler implements Handler interface.
+var _ Handler = (*SearchHandler)(nil)
+
+type SearchHandler struct {
+	Supervisor *supervisor.Supervisor
+	SQLite     *data.SQLite
+}
+
+func NewSearchHandler(su *supervisor.Supervisor) *SearchHandler {
+	return &SearchHandler{
+		Supervisor: su,
+		SQLite:     su.SQLite,
+	}
+}
+
+func (h *SearchHandler) MapRoutes(router *fiber.Group) {
+	router.Get("/", h.handleSearch)
+}
+
+// Keyword search over mock topics, instructions and question problems, with highlighted snippets.
+// Question problems are only searched in the user's own mocks, see search.Search.
+func (h *SearchHandler) handleSearch(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	req := new(schemas.SearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	if err := errs.Validate(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SEARCH_TIMEOUT)
+	defer cancel()
+
+	results, err := search.Search(ctx, h.SQLite.DB, req.Query, user.ID, req.Limit)
+	if err != nil {
+		var e errs.Error
+		if errors.As(err, &e) {
+			logging.Log(slog.LevelError, c, "Search failed", "error", e)
+
+			switch e.Code {
+			case errs.ErrDataIllegal:
+				return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+			case errs.ErrInternalFailure:
+				return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal failure"))
+			}
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Unknown error"))
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, results, ""))
+}
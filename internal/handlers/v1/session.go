@@ -7,6 +7,7 @@ import (
 	"github.com/ashtonx86/mocker/internal/data"
 	"github.com/ashtonx86/mocker/internal/errs"
 	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/session"
 	"github.com/ashtonx86/mocker/internal/supervisor"
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,25 +19,27 @@ type SessionHandler struct {
 	Supervisor *supervisor.Supervisor
 	SQLite     *data.SQLite
 }
+
 func NewSessionHandler(su *supervisor.Supervisor) *SessionHandler {
-    return &SessionHandler{
-        Supervisor: su,
-        SQLite:     su.SQLite,
-    }
+	return &SessionHandler{
+		Supervisor: su,
+		SQLite:     su.SQLite,
+	}
 }
 
 func (h *SessionHandler) MapRoutes(router *fiber.Group) {
-    router.Post("/", h.handlePOST)               
-    router.Post("/answer", h.handleAddAnswer)    
-    router.Get("/submit/:userID", h.handleSubmit)
+	router.Post("/", h.handlePOST)
+	router.Get("/", h.handleList)
+	router.Get("/:id", h.handleGET)
+	router.Post("/:id/answer", h.handleAddAnswer)
+	router.Post("/:id/submit", h.handleSubmit)
 }
 
-
 func (h *SessionHandler) handlePOST(c *fiber.Ctx) error {
-    user := auth.GetCurrentUser(c)
-    if user == nil {
-        return c.SendStatus(fiber.StatusInternalServerError)
-    }
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
 
 	req := new(schemas.SessionCreateRequest)
 	c.BodyParser(&req)
@@ -46,29 +49,58 @@ func (h *SessionHandler) handlePOST(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
 	}
 
-    sessions, err := h.Supervisor.SessionManager.New(c.Context(), req.MockID, user.ID)
-    if err != nil {
-        var e errs.Error
-        if errors.As(err, &e) {
-            switch e.Code {
-            case errs.ErrNotFound:
-                return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Mock with that ID does not exist"))
-            case errs.ErrAlreadyExists:
-                return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Session for ths user already exists"))
-            default:
-                return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal error"))
-            }
-        }
-    }
-
-    return c.Status(fiber.StatusCreated).JSON(schemas.NewAPIResponse(true, sessions, ""))
+	state, err := h.Supervisor.SessionManager.New(c.Context(), req.MockID, user.ID, req.AccessCode)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(schemas.NewAPIResponse(true, state, ""))
+}
+
+// List the current user's active sessions.
+func (h *SessionHandler) handleList(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	states, err := h.Supervisor.SessionManager.List(c.Context(), user.ID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, states, ""))
+}
+
+func (h *SessionHandler) handleGET(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errs.GenericBadRequstErr("id"), "Bad request"))
+	}
+
+	state, err := h.Supervisor.SessionManager.Get(c.Context(), sessionID, user.ID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, state, ""))
 }
 
 func (h *SessionHandler) handleAddAnswer(c *fiber.Ctx) error {
-    user := auth.GetCurrentUser(c)
-    if user != nil {
-        return c.SendStatus(fiber.StatusInternalServerError)
-    }
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errs.GenericBadRequstErr("id"), "Bad request"))
+	}
 
 	req := new(schemas.AnswerAddRequest)
 	c.BodyParser(&req)
@@ -78,55 +110,61 @@ func (h *SessionHandler) handleAddAnswer(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
 	}
 
+	err = h.Supervisor.SessionManager.AddAnswer(c.Context(), sessionID, user.ID, req.QuestionID, session.NewResponse(*req))
+	if err != nil {
+		return h.handleError(c, err)
+	}
 
-    err = h.Supervisor.SessionManager.AddAnswer(c.Context(), req.MockID, user.ID, req.QuestionID, req.OptionID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
 
-    if err != nil {
-        var e errs.Error
-        if errors.As(err, &e) {
-            switch e.Code {
-            case errs.ErrNotFound:
-                return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Mock with that ID does not exist"))
-            case errs.ErrAlreadyExists:
-                return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Session for ths user already exists"))
-            default:
-                return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal error"))
-            }
-        }
-    }
+func (h *SessionHandler) handleSubmit(c *fiber.Ctx) error {
+	user := auth.GetCurrentUser(c)
+	if user == nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
 
-    return c.SendStatus(fiber.StatusNoContent)
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errs.GenericBadRequstErr("id"), "Bad request"))
+	}
+
+	att, err := h.Supervisor.SessionManager.Submit(c.Context(), sessionID, user.ID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(schemas.NewAPIResponse(true, fiber.Map{
+		"attempt_id":  att.ID,
+		"session_id":  sessionID,
+		"user_id":     att.UserID,
+		"mock_id":     att.MockID,
+		"total_marks": att.TotalMarks,
+		"max_marks":   att.MaxMarks,
+	}, ""))
 }
 
-func (h *SessionHandler) handleSubmit(c *fiber.Ctx) error {
-    userID := c.Params("userID")
-    if userID == "" {
-        return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errs.GenericBadRequstErr("user_id"), "Bad request"))
-    }
-
-    mockID := c.Query("mock_id")
-    if mockID == "" {
-        return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(errs.GenericBadRequstErr("mock_id"), "Bad request"))
-    }
-
-    total, err := h.Supervisor.SessionManager.CalculateTotalMarks(c.Context(), h.SQLite.DB, mockID, userID)
-    if err != nil {
-        var e errs.Error
-        if errors.As(err, &e) {
-            switch e.Code {
-            case errs.ErrNotFound:
-                return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Mock with that ID does not exist"))
-            case errs.ErrAlreadyExists:
-                return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Session for ths user already exists"))
-            default:
-                return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal error"))
-            }
-        }
-    }
-
-    return c.JSON(schemas.NewAPIResponse(true, fiber.Map{
-        "user_id":   userID,
-        "mock_id":   mockID,
-        "total_marks": total,
-    }, ""))
+func (h *SessionHandler) handleError(c *fiber.Ctx, err error) error {
+	var e errs.Error
+	if errors.As(err, &e) {
+		switch e.Code {
+		case errs.ErrNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(schemas.NewErrorAPIResponse(err, "Session or mock not found"))
+		case errs.ErrAlreadyExists:
+			return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Session already exists or was already submitted"))
+		case errs.ErrDataIllegal:
+			return c.Status(fiber.StatusBadRequest).JSON(schemas.NewErrorAPIResponse(err, "Bad request"))
+		case errs.ErrExpired:
+			return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Session deadline has passed"))
+		case errs.ErrConflict:
+			return c.Status(fiber.StatusConflict).JSON(schemas.NewErrorAPIResponse(err, "Mock is not open"))
+		case errs.ErrLimitReached:
+			return c.Status(fiber.StatusTooManyRequests).JSON(schemas.NewErrorAPIResponse(err, "No attempt available"))
+		case errs.ErrForbidden:
+			return c.Status(fiber.StatusForbidden).JSON(schemas.NewErrorAPIResponse(err, "Access to this mock is restricted"))
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Internal error"))
+		}
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(schemas.NewErrorAPIResponse(err, "Unknown error"))
 }
@@ -0,0 +1,165 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInviteOnly = errs.NewError(errors.New("this mock is open to invited users only"), errs.DataErrorType, errs.ErrForbidden)
+	ErrAccessCode = errs.NewError(errors.New("a valid access code is required"), errs.DataErrorType, errs.ErrForbidden)
+)
+
+func hashAccessCode(code string) (string, error) {
+	if code == "" {
+		return "", nil
+	}
+	// bcrypt.DefaultCost = 10
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errs.NewError(err, errs.DataErrorType, errs.ErrInternalFailure)
+	}
+	return string(hash), nil
+}
+
+/*
+* Whether the user may open the mock, given the access code they supplied.
+* Its author always may, invited users may whatever the visibility.
+* The code is checked against the database, mocks from the cache carry no hash.
+ */
+func CheckAccess(ctx context.Context, db *sql.DB, m *entities.Mock, userID string, code string) error {
+	if m.AuthorID == userID {
+		return nil
+	}
+
+	switch m.Visibility {
+	case "", entities.VisibilityPublic, entities.VisibilityUnlisted:
+		return nil
+	}
+
+	invited, err := isInvited(ctx, db, m.ID, userID)
+	if err != nil {
+		return err
+	}
+	if invited {
+		return nil
+	}
+
+	if m.Visibility == entities.VisibilityInviteOnly {
+		return ErrInviteOnly
+	}
+
+	if code == "" {
+		return ErrAccessCode
+	}
+	var hash string
+	if err := db.QueryRowContext(ctx, `SELECT accessCodeHash FROM mock WHERE id = ?`, m.ID).Scan(&hash); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	if hash == "" || bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) != nil {
+		return ErrAccessCode
+	}
+	return nil
+}
+
+func isInvited(ctx context.Context, db querier, mockID string, userID string) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM mockInvitation WHERE mockID = ? AND userID = ?`, mockID, userID).Scan(&n)
+	if err != nil {
+		return false, data.SQLiteErrorComparator(err)
+	}
+	return n > 0, nil
+}
+
+// Invite users to a mock. Users already invited are left as they are.
+// Only the author or an admin may do so.
+func Invite(ctx context.Context, db *sql.DB, id string, user *entities.User, userIDs []string) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	if err := checkManager(ctx, tx, id, user); err != nil {
+		return err
+	}
+
+	stmt := `
+        INSERT INTO mockInvitation (mockID, userID, createdAt)
+        SELECT ?, ?, ?
+        WHERE NOT EXISTS (SELECT 1 FROM mockInvitation WHERE mockID = ? AND userID = ?)
+    `
+	now := time.Now()
+	for _, userID := range userIDs {
+		if _, err := tx.ExecContext(ctx, stmt, id, userID, now, id, userID); err != nil {
+			return data.SQLiteErrorComparator(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	return nil
+}
+
+// Withdraw a user's invitation. Only the author or an admin may do so.
+func Uninvite(ctx context.Context, db *sql.DB, id string, user *entities.User, userID string) error {
+	if err := checkManager(ctx, db, id, user); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM mockInvitation WHERE mockID = ? AND userID = ?`, id, userID); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	return nil
+}
+
+// List the users invited to a mock, oldest invitation first. Only the author or an admin may do so.
+func ListInvitations(ctx context.Context, db *sql.DB, id string, user *entities.User) ([]entities.MockInvitation, error) {
+	if err := checkManager(ctx, db, id, user); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT mockID, userID, createdAt FROM mockInvitation WHERE mockID = ? ORDER BY createdAt, userID`, id)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	invitations := []entities.MockInvitation{}
+	for rows.Next() {
+		var inv entities.MockInvitation
+		var createdAtStr string
+		if err := rows.Scan(&inv.MockID, &inv.UserID, &createdAtStr); err != nil {
+			return nil, err
+		}
+		createdAt, _ := utils.ParseTime(createdAtStr)
+		inv.CreatedAt = *createdAt
+
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func checkManager(ctx context.Context, db querier, id string, user *entities.User) error {
+	m, err := getMockMeta(ctx, db, id)
+	if err != nil {
+		return err
+	}
+	if !CanDelete(m, user) {
+		return errs.NewError(errors.New("only the author or an admin may manage invitations to this mock"), errs.DataErrorType, errs.ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,412 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBankQuestionInUse    = errs.NewError(errors.New("bank question is used by mocks, unlink it from them first"), errs.DataErrorType, errs.ErrConflict)
+	ErrBankStructuralChange = errs.NewError(errors.New("structural changes are not allowed while sessions for a mock using this question are active"), errs.DataErrorType, errs.ErrConflict)
+	ErrNotBankAuthor        = errs.NewError(errors.New("only the author may update this bank question"), errs.DataErrorType, errs.ErrForbidden)
+)
+
+type FullBankQuestion struct {
+	entities.BankQuestion
+	Options []entities.MockOption `json:"options"`
+	MockIDs []string              `json:"mock_ids"` // Mocks linking the question.
+}
+
+// Whether the user may see or delete the bank question, answer key included.
+func CanManageBankQuestion(q *entities.BankQuestion, user *entities.User) bool {
+	return q.AuthorID == user.ID || user.IsAdmin()
+}
+
+// Add a question to its author's bank. Its pool is left out, pools belong to the mocks linking it.
+func CreateBankQuestion(ctx context.Context, db *sql.DB, authorID string, q schemas.MockQuestionSchema) (*FullBankQuestion, error) {
+	if err := ValidateBankQuestion(q); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	entity := entities.BankQuestion{
+		ID:             uuid.NewString(),
+		AuthorID:       authorID,
+		Type:           q.Type,
+		Problem:        q.Problem,
+		Points:         q.Points,
+		Explanation:    q.Explanation,
+		NegativePoints: q.NegativePoints,
+		NumericAnswer:  q.NumericAnswer,
+		Tolerance:      q.Tolerance,
+		CreatedAt:      now,
+		LastUpdatedAt:  now,
+	}
+	if entity.Type == "" {
+		entity.Type = entities.QuestionSingleChoice
+	}
+
+	options := make([]entities.MockOption, len(q.Options))
+	for i, opt := range q.Options {
+		options[i] = newMockOption(entity.ID, opt)
+	}
+	if entity.CorrectOptionID, err = markCorrect(entity.Type, q.CorrectOptionNumber, options); err != nil {
+		return nil, err
+	}
+
+	stmt := `
+        INSERT INTO bankQuestion (id, authorID, type, problem, points, correctOptionID, explanation, negativePoints, numericAnswer, tolerance, createdAt, lastUpdatedAt)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	vals := []any{entity.ID, entity.AuthorID, entity.Type, entity.Problem, entity.Points, entity.CorrectOptionID, entity.Explanation, entity.NegativePoints, entity.NumericAnswer, entity.Tolerance, entity.CreatedAt, entity.LastUpdatedAt}
+	if _, err := tx.ExecContext(ctx, stmt, vals...); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	for _, opt := range options {
+		if err := insertOption(ctx, tx, bankOptionTable, opt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	return &FullBankQuestion{BankQuestion: entity, Options: options, MockIDs: []string{}}, nil
+}
+
+// Fetch a bank question. Only its author or an admin may do so.
+func GetBankQuestion(ctx context.Context, db *sql.DB, id string, user *entities.User) (*FullBankQuestion, error) {
+	q, err := getBankQuestion(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	if !CanManageBankQuestion(&q.BankQuestion, user) {
+		return nil, errs.NewError(errors.New("only the author or an admin may see this bank question"), errs.DataErrorType, errs.ErrForbidden)
+	}
+	return q, nil
+}
+
+// List an author's bank, oldest question first.
+func ListBankQuestions(ctx context.Context, db *sql.DB, authorID string) ([]FullBankQuestion, error) {
+	return getBankQuestions(ctx, db, "authorID", authorID)
+}
+
+/*
+* Apply an update to a bank question. Every mock linking it changes with it,
+* so structural changes are refused unless opts allows them, see UpdateOptions.
+* Points only seed new links, changing them leaves the mocks alone.
+* Only the author of the question may update it.
+ */
+func UpdateBankQuestion(ctx context.Context, db *sql.DB, id string, editorID string, req schemas.MockQuestionUpdateSchema, opts UpdateOptions) (*FullBankQuestion, error) {
+	if err := ValidateBankQuestion(newQuestionSchema(req)); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	current, err := getBankQuestion(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.AuthorID != editorID {
+		return nil, ErrNotBankAuthor
+	}
+
+	typ := req.Type
+	if typ == "" {
+		typ = entities.QuestionSingleChoice
+	}
+
+	now := time.Now()
+	cur := FullMockQuestion{MockQuestion: entities.MockQuestion{ID: current.ID}, Options: current.Options}
+	correctOptionID, structural, err := diffQuestionOptions(ctx, tx, bankOptionTable, cur, typ, req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	grading := current.QuestionType() != typ || current.CorrectOptionID != correctOptionID ||
+		!equalFloatPtr(current.NegativePoints, req.NegativePoints) || !equalFloatPtr(current.NumericAnswer, req.NumericAnswer) || current.Tolerance != req.Tolerance
+	structural = structural || grading
+
+	// Read in this transaction, so a mock that linked the question since the caller looked is caught.
+	unchecked := slices.ContainsFunc(current.MockIDs, func(mockID string) bool {
+		return !slices.Contains(opts.LinkedMocks, mockID)
+	})
+	if structural && (!opts.AllowStructural || unchecked) {
+		return nil, ErrBankStructuralChange
+	}
+
+	stmt := `
+        UPDATE bankQuestion
+        SET type = ?, problem = ?, points = ?, correctOptionID = ?, explanation = ?, negativePoints = ?, numericAnswer = ?, tolerance = ?, lastUpdatedAt = ?
+        WHERE id = ?
+    `
+	vals := []any{typ, req.Problem, req.Points, correctOptionID, req.Explanation, req.NegativePoints, req.NumericAnswer, req.Tolerance, now, id}
+	if _, err := tx.ExecContext(ctx, stmt, vals...); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	// A new version retires the cached copies of the mocks linking the question, see Cache.
+	if _, err := tx.ExecContext(ctx, `UPDATE mock SET lastUpdatedAt = ? WHERE id IN (SELECT mockID FROM mockBankQuestion WHERE questionID = ?)`, now, id); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	return getBankQuestion(ctx, db, id)
+}
+
+// Remove a question from the bank. Questions still linked by a mock, archived or not, are kept.
+// Only its author or an admin may do so.
+func DeleteBankQuestion(ctx context.Context, db *sql.DB, id string, user *entities.User) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	q, err := getBankQuestion(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if !CanManageBankQuestion(&q.BankQuestion, user) {
+		return errs.NewError(errors.New("only the author or an admin may delete this bank question"), errs.DataErrorType, errs.ErrForbidden)
+	}
+	if len(q.MockIDs) > 0 {
+		return ErrBankQuestionInUse
+	}
+
+	// Its options go with it.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bankQuestion WHERE id = ?`, id); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	return nil
+}
+
+func getBankQuestion(ctx context.Context, db querier, id string) (*FullBankQuestion, error) {
+	questions, err := getBankQuestions(ctx, db, "id", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, errs.NewError(fmt.Errorf("bank question %q not found", id), errs.DataErrorType, errs.ErrNotFound)
+	}
+	return &questions[0], nil
+}
+
+// Load the bank questions whose column equals the value, with their options and links.
+// column is "id" or "authorID", never user input.
+func getBankQuestions(ctx context.Context, db querier, column string, value string) ([]FullBankQuestion, error) {
+	qStmt := fmt.Sprintf(`
+        SELECT id, authorID, type, problem, points, correctOptionID, explanation, negativePoints, numericAnswer, tolerance, createdAt, lastUpdatedAt
+        FROM bankQuestion
+        WHERE %s = ?
+        ORDER BY createdAt, id
+    `, column)
+	rows, err := db.QueryContext(ctx, qStmt, value)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	questions := []FullBankQuestion{}
+	for rows.Next() {
+		var q entities.BankQuestion
+
+		var createdAtStr, lastUpdatedAtStr string
+		var negativePoints, numericAnswer sql.NullFloat64
+
+		if err := rows.Scan(
+			&q.ID,
+			&q.AuthorID,
+			&q.Type,
+			&q.Problem,
+			&q.Points,
+			&q.CorrectOptionID,
+			&q.Explanation,
+			&negativePoints,
+			&numericAnswer,
+			&q.Tolerance,
+			&createdAtStr,
+			&lastUpdatedAtStr,
+		); err != nil {
+			return nil, err
+		}
+
+		createdAt, _ := utils.ParseTime(createdAtStr)
+		lastUpdatedAt, _ := utils.ParseTime(lastUpdatedAtStr)
+		q.CreatedAt = *createdAt
+		q.LastUpdatedAt = *lastUpdatedAt
+
+		if negativePoints.Valid {
+			q.NegativePoints = &negativePoints.Float64
+		}
+		if numericAnswer.Valid {
+			q.NumericAnswer = &numericAnswer.Float64
+		}
+
+		questions = append(questions, FullBankQuestion{BankQuestion: q, MockIDs: []string{}})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	optStmt := fmt.Sprintf(`
+        SELECT o.id, o.number, o.option, o.isCorrect, o.match, o.matchID, o.questionID, o.createdAt, o.lastUpdatedAt
+        FROM bankOption o
+        JOIN bankQuestion q ON q.id = o.questionID
+        WHERE q.%s = ?
+        ORDER BY o.questionID, o.number
+    `, column)
+	optRows, err := db.QueryContext(ctx, optStmt, value)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer optRows.Close()
+
+	options, err := scanOptions(optRows)
+	if err != nil {
+		return nil, err
+	}
+	optRows.Close()
+
+	linkStmt := fmt.Sprintf(`
+        SELECT l.questionID, l.mockID
+        FROM mockBankQuestion l
+        JOIN bankQuestion q ON q.id = l.questionID
+        WHERE q.%s = ?
+        ORDER BY l.mockID
+    `, column)
+	linkRows, err := db.QueryContext(ctx, linkStmt, value)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer linkRows.Close()
+
+	// [K : questionID] [V : mockIDs]
+	links := make(map[string][]string)
+	for linkRows.Next() {
+		var questionID, mockID string
+		if err := linkRows.Scan(&questionID, &mockID); err != nil {
+			return nil, err
+		}
+		links[questionID] = append(links[questionID], mockID)
+	}
+	if err := linkRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range questions {
+		questions[i].Options = options[questions[i].ID]
+		if mockIDs, ok := links[questions[i].ID]; ok {
+			questions[i].MockIDs = mockIDs
+		}
+	}
+	return questions, nil
+}
+
+/*
+* Replace the bank questions linked into a mock. Only questions from the bank
+* of the mock's author may be linked. Reports whether the change was
+* structural: a question added, removed or worth different points.
+* Moving one is not.
+ */
+func replaceBankLinks(ctx context.Context, tx *sql.Tx, mockID string, authorID string, links []schemas.MockBankQuestionSchema, now time.Time) (bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT questionID, points FROM mockBankQuestion WHERE mockID = ?`, mockID)
+	if err != nil {
+		return false, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	// [K : questionID] [V : points]
+	existing := make(map[string]int)
+	for rows.Next() {
+		var questionID string
+		var points int
+		if err := rows.Scan(&questionID, &points); err != nil {
+			return false, err
+		}
+		existing[questionID] = points
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	rows.Close()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mockBankQuestion WHERE mockID = ?`, mockID); err != nil {
+		return false, data.SQLiteErrorComparator(err)
+	}
+
+	structural := false
+	insertStmt := `INSERT INTO mockBankQuestion (mockID, questionID, points, position, pool, createdAt) VALUES (?, ?, ?, ?, ?, ?)`
+	for _, link := range links {
+		var owner string
+		var points int
+		err := tx.QueryRowContext(ctx, `SELECT authorID, points FROM bankQuestion WHERE id = ?`, link.QuestionID).Scan(&owner, &points)
+		if err != nil && err != sql.ErrNoRows {
+			return false, data.SQLiteErrorComparator(err)
+		}
+		if err == sql.ErrNoRows || owner != authorID {
+			return false, errs.NewError(fmt.Errorf("bank question %q does not exist or belongs to another author", link.QuestionID), errs.DataErrorType, errs.ErrDataIllegal)
+		}
+
+		if link.Points > 0 {
+			points = link.Points
+		}
+		if cur, ok := existing[link.QuestionID]; !ok || cur != points {
+			structural = true
+		}
+		delete(existing, link.QuestionID)
+
+		if _, err := tx.ExecContext(ctx, insertStmt, mockID, link.QuestionID, points, link.Position, link.Pool, now); err != nil {
+			return false, data.SQLiteErrorComparator(err)
+		}
+	}
+
+	return structural || len(existing) > 0, nil
+}
+
+// A mock keeps at least one question, its own or from the bank.
+func checkHasQuestions(ctx context.Context, tx *sql.Tx, mockID string) error {
+	stmt := `SELECT (SELECT COUNT(*) FROM mockQuestion WHERE mockID = ?) + (SELECT COUNT(*) FROM mockBankQuestion WHERE mockID = ?)`
+
+	var n int
+	if err := tx.QueryRowContext(ctx, stmt, mockID, mockID).Scan(&n); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	if n == 0 {
+		return errs.NewError(errors.New("a mock needs at least one question"), errs.DataErrorType, errs.ErrDataIllegal)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package mock
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Entries are versioned, stale ones are never read and only wait to expire.
+	CacheTTL = 30 * time.Minute
+
+	// Bounds how long an instance that missed an invalidation serves a stale mock.
+	LocalCacheTTL  = 30 * time.Second
+	LocalCacheSize = 256
+
+	// Carries the IDs of updated or deleted mocks to every API instance.
+	InvalidationChannel = "mock:invalidate"
+)
+
+/*
+* Read-through cache of full mocks, in two layers:
+*   in-process : LRU keyed by mock ID, evicted on invalidation messages
+*   Redis      : "mock:{id}:full:<version>", version being LastUpdatedAt
+* A Redis lookup costs one query for the "mock" row instead of loading every
+* question and option. Every update or archive bumps LastUpdatedAt, so an
+* outdated Redis entry is never read.
+* Mocks returned by Get are shared and must not be modified.
+ */
+type Cache struct {
+	DB    *sql.DB
+	Redis *data.Redis // May be nil, leaving only the in-process layer.
+
+	local *lru
+}
+
+func NewCache(db *sql.DB, redisClient *data.Redis) *Cache {
+	return &Cache{
+		DB:    db,
+		Redis: redisClient,
+		local: newLRU(LocalCacheSize, LocalCacheTTL),
+	}
+}
+
+func cacheKey(id string, version string) string {
+	return "mock:{" + id + "}:full:" + version
+}
+
+func cacheVersion(m entities.Mock) string {
+	return strconv.FormatInt(m.LastUpdatedAt.UnixNano(), 10)
+}
+
+// Fetch a full mock, from the cache when possible.
+func (c *Cache) Get(ctx context.Context, id string) (*FullMock, error) {
+	if m, ok := c.local.get(id, time.Now()); ok {
+		return m, nil
+	}
+
+	if c.Redis == nil {
+		return c.load(ctx, id)
+	}
+
+	meta, err := GetMockMeta(ctx, c.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	key := cacheKey(id, cacheVersion(*meta))
+
+	raw, err := c.Redis.Client.Get(ctx, key).Bytes()
+	if err == nil {
+		var m FullMock
+		if err := json.Unmarshal(raw, &m); err == nil {
+			c.local.put(id, &m, time.Now())
+			return &m, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		slog.Warn("[pkg mock : func Get] mock cache unavailable", "mock_id", id, "error", err)
+	}
+
+	m, err := c.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Written under the version it was loaded at, which may be newer than the one looked up.
+	if raw, err := json.Marshal(m); err == nil {
+		if err := c.Redis.Client.Set(ctx, cacheKey(id, cacheVersion(m.Mock)), raw, CacheTTL).Err(); err != nil {
+			slog.Warn("[pkg mock : func Get] failed to cache mock", "mock_id", id, "error", err)
+		}
+	}
+	return m, nil
+}
+
+func (c *Cache) load(ctx context.Context, id string) (*FullMock, error) {
+	m, err := GetMock(ctx, c.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	c.local.put(id, m, time.Now())
+	return m, nil
+}
+
+// Drop a mock from every instance's cache. Call after updating or deleting it.
+func (c *Cache) Invalidate(ctx context.Context, id string) error {
+	c.local.remove(id)
+
+	if c.Redis == nil {
+		return nil
+	}
+	return data.RedisErrorComparator(c.Redis.Client.Publish(ctx, InvalidationChannel, id).Err())
+}
+
+// Evict mocks invalidated by other instances until the context is cancelled.
+func (c *Cache) Listen(ctx context.Context) {
+	if c.Redis == nil {
+		return
+	}
+
+	sub := c.Redis.Client.Subscribe(ctx, InvalidationChannel)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub.Channel():
+			if !ok {
+				return
+			}
+			c.local.remove(msg.Payload)
+		}
+	}
+}
+
+// Fixed-size, least recently used first out, with entries expiring after ttl.
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List               // Front is the most recently used.
+	entries map[string]*list.Element // [K : mock ID] [V : *lruEntry]
+}
+
+type lruEntry struct {
+	id        string
+	mock      *FullMock
+	expiresAt time.Time
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (l *lru) get(id string, now time.Time) (*FullMock, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if now.After(entry.expiresAt) {
+		l.order.Remove(elem)
+		delete(l.entries, id)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+	return entry.mock, true
+}
+
+func (l *lru) put(id string, m *FullMock, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[id]; ok {
+		elem.Value = &lruEntry{id: id, mock: m, expiresAt: now.Add(l.ttl)}
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[id] = l.order.PushFront(&lruEntry{id: id, mock: m, expiresAt: now.Add(l.ttl)})
+
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).id)
+	}
+}
+
+func (l *lru) remove(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[id]; ok {
+		l.order.Remove(elem)
+		delete(l.entries, id)
+	}
+}
@@ -0,0 +1,77 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+)
+
+var (
+	ErrMockArchived     = errs.NewError(errors.New("mock has been archived"), errs.DataErrorType, errs.ErrNotFound)
+	ErrNotMockDeletable = errs.NewError(errors.New("only the author or an admin may delete this mock"), errs.DataErrorType, errs.ErrForbidden)
+)
+
+type DeleteOptions struct {
+	// Remove the mock and everything that depends on it, attempts included,
+	// instead of archiving it.
+	Hard bool
+}
+
+// Whether the user may delete or archive the mock.
+func CanDelete(m *entities.Mock, user *entities.User) bool {
+	return m.AuthorID == user.ID || user.IsAdmin()
+}
+
+// Archive or delete a mock. Only its author or an admin may do so.
+func DeleteMock(ctx context.Context, db *sql.DB, id string, user *entities.User, opts DeleteOptions) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	m, err := getMockMeta(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if !CanDelete(m, user) {
+		return ErrNotMockDeletable
+	}
+
+	if opts.Hard {
+		err = purgeMock(ctx, tx, id)
+	} else {
+		err = archiveMock(ctx, tx, id, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	return nil
+}
+
+// Archiving an already archived mock keeps its original timestamp.
+func archiveMock(ctx context.Context, tx *sql.Tx, id string, now time.Time) error {
+	stmt := `UPDATE mock SET status = ?, archivedAt = ?, lastUpdatedAt = ? WHERE id = ? AND archivedAt IS NULL`
+	if _, err := tx.ExecContext(ctx, stmt, entities.MockArchived, now, now, id); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	return nil
+}
+
+// Every row that refers to the mock goes with it, see the ON DELETE CASCADE of the entities.
+func purgeMock(ctx context.Context, tx *sql.Tx, id string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mock WHERE id = ?`, id); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+func TestDeleteMock(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author", "guest", "candidate")
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+	admin := &entities.User{ID: "admin", Role: entities.RoleAdmin}
+
+	option := func(number int, text string) schemas.MockOptionSchema {
+		return schemas.MockOptionSchema{Number: number, Option: text}
+	}
+	banked, err := mock.CreateBankQuestion(ctx, db, author.ID, schemas.MockQuestionSchema{Problem: "Banked", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{option(1, "a"), option(2, "b")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A mock with a row in every table that depends on it.
+	create := func() string {
+		created, err := mock.CreateMock(ctx, db, schemas.MockCreateRequest{
+			Topic: "Doomed", Instructions: "i", TimeMins: 30, AuthorID: author.ID, Tags: []string{"go"},
+			Pools:         []schemas.MockPoolSchema{{Name: "easy", Draw: 1}},
+			Questions:     []schemas.MockQuestionSchema{{Problem: "Own", Points: 1, CorrectOptionNumber: 1, Pool: "easy", Options: []schemas.MockOptionSchema{option(1, "a"), option(2, "b")}}},
+			BankQuestions: []schemas.MockBankQuestionSchema{{QuestionID: banked.ID, Points: 2}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.Invite(ctx, db, created.ID, author, []string{"guest"}); err != nil {
+			t.Fatal(err)
+		}
+
+		now := time.Now()
+		att := entities.Attempt{ID: created.ID + "-attempt", SessionID: created.ID + "-session", MockID: created.ID, UserID: "candidate", StartedAt: now, SubmittedAt: now}
+		answers := []entities.AttemptAnswer{{ID: created.ID + "-answer", AttemptID: att.ID, QuestionID: "q"}}
+		if _, err := attempt.CreateAttempt(ctx, db, att, answers); err != nil {
+			t.Fatal(err)
+		}
+		return created.ID
+	}
+
+	// [K : table] [V : rows left for the mock]
+	// Children whose parent is gone count too, they can no longer be traced back to a mock.
+	leftovers := func(id string) map[string]int {
+		queries := map[string]string{
+			"mock":             `SELECT COUNT(*) FROM mock WHERE id = ?`,
+			"mockQuestion":     `SELECT COUNT(*) FROM mockQuestion WHERE mockID = ?`,
+			"mockOption":       `SELECT COUNT(*) FROM mockOption WHERE questionID IN (SELECT id FROM mockQuestion WHERE mockID = ?) OR questionID NOT IN (SELECT id FROM mockQuestion)`,
+			"mockTag":          `SELECT COUNT(*) FROM mockTag WHERE mockID = ?`,
+			"mockInvitation":   `SELECT COUNT(*) FROM mockInvitation WHERE mockID = ?`,
+			"mockPool":         `SELECT COUNT(*) FROM mockPool WHERE mockID = ?`,
+			"mockBankQuestion": `SELECT COUNT(*) FROM mockBankQuestion WHERE mockID = ?`,
+			"attempt":          `SELECT COUNT(*) FROM attempt WHERE mockID = ?`,
+			"attemptAnswer":    `SELECT COUNT(*) FROM attemptAnswer WHERE attemptID IN (SELECT id FROM attempt WHERE mockID = ?) OR attemptID NOT IN (SELECT id FROM attempt)`,
+		}
+		rows := make(map[string]int, len(queries))
+		for table, query := range queries {
+			var n int
+			if err := db.QueryRowContext(ctx, query, id).Scan(&n); err != nil {
+				t.Fatalf("%s :: %v", table, err)
+			}
+			if n > 0 {
+				rows[table] = n
+			}
+		}
+		return rows
+	}
+
+	id := create()
+	if got := leftovers(id); len(got) != 9 {
+		t.Fatalf("expected rows in every table before deleting, got %v", got)
+	}
+
+	forbidden := errs.Error{Code: errs.ErrForbidden, Type: errs.DataErrorType.String()}
+	if err := mock.DeleteMock(ctx, db, id, &entities.User{ID: "someone", Role: entities.RoleUser}, mock.DeleteOptions{}); !errors.Is(err, forbidden) {
+		t.Fatalf("expected only the author or an admin to delete, got %v", err)
+	}
+
+	if err := mock.DeleteMock(ctx, db, id, author, mock.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	archived, err := mock.GetMock(ctx, db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived.Status != entities.MockArchived || archived.ArchivedAt == nil || len(leftovers(id)) != 9 {
+		t.Fatalf("expected the mock archived with everything kept, got %+v", archived.Mock)
+	}
+
+	if err := mock.DeleteMock(ctx, db, id, author, mock.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	again, err := mock.GetMock(ctx, db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.ArchivedAt.Equal(*archived.ArchivedAt) {
+		t.Errorf("expected archiving twice to keep the first timestamp, got %v then %v", archived.ArchivedAt, again.ArchivedAt)
+	}
+
+	other := create()
+	if err := mock.DeleteMock(ctx, db, id, admin, mock.DeleteOptions{Hard: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := leftovers(id); len(got) != 0 {
+		t.Errorf("expected nothing left of the mock, got %v", got)
+	}
+	if got := leftovers(other); len(got) != 9 {
+		t.Errorf("expected other mocks to be left alone, got %v", got)
+	}
+	if _, err := mock.GetBankQuestion(ctx, db, banked.ID, author); err != nil {
+		t.Errorf("expected the bank question to outlive the mocks linking it, got %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/utils"
+)
+
+const (
+	DefaultListLimit = 20
+
+	// How go-sqlite3 writes time.Time values. Stored timestamps compare correctly as text
+	// as long as they share a timezone, which is what cursors and date filters rely on.
+	storedTimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+)
+
+var mockColumns = []string{"id", "topic", "instructions", "timeMins", "authorID", "reviewPolicy", "negativeMarking", "floorAtZero", "partialCredit", "status", "visibility", "shuffleQuestions", "shuffleOptions", "maxAttempts", "cooldownMins", "opensAt", "closesAt", "timezone", "archivedAt", "createdAt", "lastUpdatedAt"}
+
+// [K : sort parameter] [V : column]
+var sortColumns = map[string]string{
+	"created_at": "createdAt",
+	"topic":      "topic",
+	"time_mins":  "timeMins",
+}
+
+type MockListItem struct {
+	entities.Mock
+	Tags []string `json:"tags"`
+}
+
+type MockPage struct {
+	Mocks      []MockListItem `json:"mocks"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// Position of the last row of a page: its sort value and ID, which breaks ties.
+type listCursor struct {
+	Value any    `json:"v"`
+	ID    string `json:"id"`
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// List mocks that are not archived, one page at a time.
+// Drafts are left out unless the viewer wrote them, and so are mocks that are
+// not public unless the viewer wrote them or was invited.
+func ListMocks(ctx context.Context, db *sql.DB, req schemas.MockListRequest) (*MockPage, error) {
+	sort := req.Sort
+	if sort == "" {
+		sort = "created_at"
+	}
+	column := sortColumns[sort]
+
+	desc := sort == "created_at"
+	if req.Order != "" {
+		desc = req.Order == "desc"
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+
+	args := data.SQLSelectArgs{
+		Where: data.SQLWhereClause{Where: entities.Mock{AuthorID: req.AuthorID, Status: req.Status}},
+		Conditions: []data.SQLCondition{
+			{Expr: "archivedAt IS NULL"},
+			{Expr: "status != ? OR authorID = ?", Args: []any{entities.MockDraft, req.ViewerID}},
+			{
+				Expr: "visibility = ? OR authorID = ? OR id IN (SELECT mockID FROM mockInvitation WHERE userID = ?)",
+				Args: []any{entities.VisibilityPublic, req.ViewerID, req.ViewerID},
+			},
+		},
+		OrderBy: []data.SQLOrder{{Column: column, Desc: desc}, {Column: "id", Desc: desc}},
+		Limit:   limit + 1, // One extra row tells whether there is a next page.
+	}
+
+	filters, err := listConditions(req)
+	if err != nil {
+		return nil, err
+	}
+	args.Conditions = append(args.Conditions, filters...)
+
+	if req.Cursor != "" {
+		cur, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		args.Conditions = append(args.Conditions, data.SQLCondition{
+			Expr: column + " " + op + " ? OR (" + column + " = ? AND id " + op + " ?)",
+			Args: []any{cur.Value, cur.Value, cur.ID},
+		})
+	}
+
+	stmt, vals, err := data.PrepareSelectStmt("mock", mockColumns, args)
+	if err != nil {
+		return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+
+	rows, err := db.QueryContext(ctx, stmt, vals...)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	page := &MockPage{Mocks: []MockListItem{}}
+	for rows.Next() {
+		m, err := scanMock(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Mocks = append(page.Mocks, MockListItem{Mock: *m})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Mocks) > limit {
+		page.Mocks = page.Mocks[:limit]
+		page.NextCursor = encodeCursor(page.Mocks[limit-1].Mock, sort)
+	}
+
+	ids := make([]string, len(page.Mocks))
+	for i, m := range page.Mocks {
+		ids[i] = m.ID
+	}
+	tags, err := getMockTags(ctx, db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Mocks {
+		page.Mocks[i].Tags = tags[page.Mocks[i].ID]
+	}
+
+	return page, nil
+}
+
+func listConditions(req schemas.MockListRequest) ([]data.SQLCondition, error) {
+	var conds []data.SQLCondition
+
+	if req.Topic != "" {
+		escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+		conds = append(conds, data.SQLCondition{
+			Expr: `topic LIKE ? ESCAPE '\'`,
+			Args: []any{"%" + escaper.Replace(req.Topic) + "%"},
+		})
+	}
+
+	if tags := normalizeTags(strings.Split(req.Tags, ",")); len(tags) > 0 {
+		placeholders := make([]string, len(tags))
+		args := make([]any, 0, len(tags)+1)
+		for i, tag := range tags {
+			placeholders[i] = "?"
+			args = append(args, tag)
+		}
+		args = append(args, len(tags))
+
+		conds = append(conds, data.SQLCondition{
+			Expr: `id IN (SELECT mockID FROM mockTag WHERE tag IN (` + strings.Join(placeholders, ", ") + `) GROUP BY mockID HAVING COUNT(DISTINCT tag) = ?)`,
+			Args: args,
+		})
+	}
+
+	dates := []struct {
+		value string
+		expr  string
+	}{
+		{req.CreatedAfter, "createdAt >= ?"},
+		{req.CreatedBefore, "createdAt < ?"},
+	}
+	for _, d := range dates {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, d.value)
+		if err != nil {
+			return nil, errs.NewError(err, errs.DataErrorType, errs.ErrDataIllegal)
+		}
+		conds = append(conds, data.SQLCondition{Expr: d.expr, Args: []any{t.In(time.Local).Format(storedTimeLayout)}})
+	}
+
+	if req.MinTimeMins > 0 {
+		conds = append(conds, data.SQLCondition{Expr: "timeMins >= ?", Args: []any{req.MinTimeMins}})
+	}
+	if req.MaxTimeMins > 0 {
+		conds = append(conds, data.SQLCondition{Expr: "timeMins <= ?", Args: []any{req.MaxTimeMins}})
+	}
+
+	return conds, nil
+}
+
+func encodeCursor(m entities.Mock, sort string) string {
+	cur := listCursor{ID: m.ID}
+	switch sort {
+	case "topic":
+		cur.Value = m.Topic
+	case "time_mins":
+		cur.Value = m.TimeMins
+	default:
+		cur.Value = m.CreatedAt.Format(storedTimeLayout)
+	}
+
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*listCursor, error) {
+	invalid := errs.NewError(errors.New("invalid cursor"), errs.DataErrorType, errs.ErrDataIllegal)
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var cur listCursor
+	if err := json.Unmarshal(b, &cur); err != nil || cur.ID == "" || cur.Value == nil {
+		return nil, invalid
+	}
+	return &cur, nil
+}
+
+// Scan a row selected with mockColumns.
+func scanMock(row scanner) (*entities.Mock, error) {
+	var mock entities.Mock
+	var createdAtString, lastUpdatedAtString string
+	var opensAtString, closesAtString, archivedAtString sql.NullString
+
+	err := row.Scan(
+		&mock.ID,
+		&mock.Topic,
+		&mock.Instructions,
+		&mock.TimeMins,
+		&mock.AuthorID,
+		&mock.ReviewPolicy,
+		&mock.NegativeMarking,
+		&mock.FloorAtZero,
+		&mock.PartialCredit,
+		&mock.Status,
+		&mock.Visibility,
+		&mock.ShuffleQuestions,
+		&mock.ShuffleOptions,
+		&mock.MaxAttempts,
+		&mock.CooldownMins,
+		&opensAtString,
+		&closesAtString,
+		&mock.Timezone,
+		&archivedAtString,
+		&createdAtString,
+		&lastUpdatedAtString,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, _ := utils.ParseTime(createdAtString)
+	lastUpdatedAt, _ := utils.ParseTime(lastUpdatedAtString)
+
+	mock.CreatedAt = *createdAt
+	mock.LastUpdatedAt = *lastUpdatedAt
+
+	if opensAtString.Valid {
+		mock.OpensAt, _ = utils.ParseTime(opensAtString.String)
+	}
+	if closesAtString.Valid {
+		mock.ClosesAt, _ = utils.ParseTime(closesAtString.String)
+	}
+	if archivedAtString.Valid {
+		mock.ArchivedAt, _ = utils.ParseTime(archivedAtString.String)
+	}
+
+	return &mock, nil
+}
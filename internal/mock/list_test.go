@@ -0,0 +1,276 @@
+package mock_test
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+type listedMock struct {
+	topic     string
+	timeMins  int
+	tags      []string
+	createdAt time.Time
+	draft     bool
+}
+
+// Create a mock with the given creation time, published unless it is a draft.
+func createListedMock(t *testing.T, db *sql.DB, l listedMock) string {
+	ctx := context.Background()
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+	testutil.CreateUsers(t, db, author.ID)
+
+	created, err := mock.CreateMock(ctx, db, schemas.MockCreateRequest{
+		Topic: l.topic, Instructions: "i", TimeMins: l.timeMins, AuthorID: author.ID, Tags: l.tags,
+		Questions: []schemas.MockQuestionSchema{{Problem: "p", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.draft {
+		if _, err := mock.SetStatus(ctx, db, created.ID, author, entities.MockPublished); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE mock SET createdAt = ? WHERE id = ?`, l.createdAt, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	return created.ID
+}
+
+// Every page of a listing, following the cursors.
+func listAll(t *testing.T, db *sql.DB, req schemas.MockListRequest) []mock.MockListItem {
+	var all []mock.MockListItem
+	for range 20 {
+		page, err := mock.ListMocks(context.Background(), db, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Mocks) > req.Limit && req.Limit > 0 {
+			t.Fatalf("expected at most %d mocks per page, got %d", req.Limit, len(page.Mocks))
+		}
+		all = append(all, page.Mocks...)
+		if page.NextCursor == "" {
+			return all
+		}
+		req.Cursor = page.NextCursor
+	}
+	t.Fatal("expected the cursors to reach the last page")
+	return nil
+}
+
+func listedIDs(mocks []mock.MockListItem) []string {
+	ids := make([]string, len(mocks))
+	for i, m := range mocks {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func TestListMocksPagination(t *testing.T) {
+	db := testutil.NewDB(t)
+
+	// Ties on every sort key, so pages have to break them by ID.
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	var mocks []mock.MockListItem
+	for _, l := range []listedMock{
+		{topic: "Go", timeMins: 30, createdAt: base},
+		{topic: "Rust", timeMins: 60, createdAt: base.Add(time.Hour)},
+		{topic: "Go", timeMins: 30, createdAt: base.Add(2 * time.Hour)},
+		{topic: "Algebra", timeMins: 90, createdAt: base.Add(2 * time.Hour)},
+		{topic: "Zig", timeMins: 30, createdAt: base.Add(3 * time.Hour)},
+		{topic: "Go", timeMins: 60, createdAt: base.Add(4 * time.Hour)},
+		{topic: "Biology", timeMins: 15, createdAt: base.Add(5 * time.Hour)},
+	} {
+		id := createListedMock(t, db, l)
+		mocks = append(mocks, mock.MockListItem{Mock: entities.Mock{ID: id, Topic: l.topic, TimeMins: l.timeMins, CreatedAt: l.createdAt}})
+	}
+
+	keys := map[string]func(a, b mock.MockListItem) int{
+		"created_at": func(a, b mock.MockListItem) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		"topic":      func(a, b mock.MockListItem) int { return cmp.Compare(a.Topic, b.Topic) },
+		"time_mins":  func(a, b mock.MockListItem) int { return cmp.Compare(a.TimeMins, b.TimeMins) },
+	}
+	for sort, key := range keys {
+		for _, order := range []string{"asc", "desc"} {
+			t.Run(sort+" "+order, func(t *testing.T) {
+				want := slices.Clone(mocks)
+				slices.SortFunc(want, func(a, b mock.MockListItem) int {
+					c := cmp.Or(key(a, b), cmp.Compare(a.ID, b.ID))
+					if order == "desc" {
+						return -c
+					}
+					return c
+				})
+
+				for _, limit := range []int{1, 2, 3, 7} {
+					got := listAll(t, db, schemas.MockListRequest{Sort: sort, Order: order, Limit: limit, ViewerID: "viewer"})
+					if !slices.Equal(listedIDs(got), listedIDs(want)) {
+						t.Fatalf("limit %d: expected %v, got %v", limit, listedIDs(want), listedIDs(got))
+					}
+				}
+			})
+		}
+	}
+
+	// Newest first by default.
+	page, err := mock.ListMocks(context.Background(), db, schemas.MockListRequest{Limit: 1, ViewerID: "viewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Mocks[0].Topic != "Biology" || page.NextCursor == "" {
+		t.Errorf("expected the newest mock first with a next page, got %+v", page)
+	}
+
+	_, err = mock.ListMocks(context.Background(), db, schemas.MockListRequest{Cursor: "not-a-cursor"})
+	if !errors.Is(err, errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}) {
+		t.Errorf("expected an invalid cursor to be refused, got %v", err)
+	}
+}
+
+func TestListMocksFilters(t *testing.T) {
+	db := testutil.NewDB(t)
+
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	ids := map[string]string{}
+	for name, l := range map[string]listedMock{
+		"go":       {topic: "Go basics", timeMins: 30, tags: []string{"go", "backend"}, createdAt: base},
+		"advanced": {topic: "Advanced Go", timeMins: 90, tags: []string{"go"}, createdAt: base.Add(24 * time.Hour)},
+		"percent":  {topic: "100% SQL", timeMins: 45, tags: []string{"sql", "backend"}, createdAt: base.Add(48 * time.Hour)},
+		"css":      {topic: "CSS", timeMins: 10, createdAt: base.Add(72 * time.Hour)},
+	} {
+		ids[name] = createListedMock(t, db, l)
+	}
+
+	tests := []struct {
+		name string
+		req  schemas.MockListRequest
+		want []string
+	}{
+		{"topic", schemas.MockListRequest{Topic: "go"}, []string{"advanced", "go"}},
+		{"topic with wildcards taken literally", schemas.MockListRequest{Topic: "0%"}, []string{"percent"}},
+		{"one tag", schemas.MockListRequest{Tags: "backend"}, []string{"percent", "go"}},
+		{"every tag", schemas.MockListRequest{Tags: "go, Backend"}, []string{"go"}},
+		{"created after", schemas.MockListRequest{CreatedAfter: base.Add(24 * time.Hour).Format(time.RFC3339)}, []string{"css", "percent", "advanced"}},
+		{"created before", schemas.MockListRequest{CreatedBefore: base.Add(24 * time.Hour).Format(time.RFC3339)}, []string{"go"}},
+		{"created between in another timezone", schemas.MockListRequest{
+			CreatedAfter:  base.Add(time.Hour).In(time.FixedZone("", 5*3600)).Format(time.RFC3339),
+			CreatedBefore: base.Add(72 * time.Hour).In(time.FixedZone("", -8*3600)).Format(time.RFC3339),
+		}, []string{"percent", "advanced"}},
+		{"minimum duration", schemas.MockListRequest{MinTimeMins: 45}, []string{"percent", "advanced"}},
+		{"maximum duration", schemas.MockListRequest{MaxTimeMins: 30}, []string{"css", "go"}},
+		{"duration range", schemas.MockListRequest{MinTimeMins: 30, MaxTimeMins: 45}, []string{"percent", "go"}},
+		{"everything at once", schemas.MockListRequest{Topic: "go", Tags: "go", MinTimeMins: 60, CreatedAfter: base.Format(time.RFC3339)}, []string{"advanced"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The handler validates the request first, which must not choke on any of it.
+			if err := errs.Validate(&tt.req); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.req.ViewerID = "viewer"
+			page, err := mock.ListMocks(context.Background(), db, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]string, len(tt.want))
+			for i, name := range tt.want {
+				want[i] = ids[name]
+			}
+			if got := listedIDs(page.Mocks); !slices.Equal(got, want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	for _, date := range []string{"2026-01-01", "yesterday"} {
+		_, err := mock.ListMocks(context.Background(), db, schemas.MockListRequest{CreatedAfter: date})
+		if !errors.Is(err, errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}) {
+			t.Errorf("expected %q to be refused as a date, got %v", date, err)
+		}
+	}
+
+	page, err := mock.ListMocks(context.Background(), db, schemas.MockListRequest{Tags: "sql", ViewerID: "viewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := page.Mocks[0].Tags; !slices.Equal(tags, []string{"backend", "sql"}) && !slices.Equal(tags, []string{"sql", "backend"}) {
+		t.Errorf("expected the mock's tags with it, got %v", tags)
+	}
+}
+
+func TestListMocksHiding(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "guest")
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	ids := map[string]string{}
+	for i, name := range []string{"public", "draft", "closed", "archived", "unlisted", "invite_only", "code"} {
+		ids[name] = createListedMock(t, db, listedMock{topic: name, timeMins: 30, createdAt: base.Add(time.Duration(i) * time.Hour), draft: name == "draft"})
+	}
+
+	if _, err := mock.SetStatus(ctx, db, ids["closed"], author, entities.MockClosed); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.DeleteMock(ctx, db, ids["archived"], author, mock.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, visibility := range []string{entities.VisibilityUnlisted, entities.VisibilityInviteOnly, entities.VisibilityCode} {
+		req := schemas.MockUpdateRequest{Visibility: &visibility}
+		if visibility == entities.VisibilityCode {
+			code := "secret"
+			req.AccessCode = &code
+		}
+		if _, err := mock.UpdateMock(ctx, db, ids[visibility], author.ID, req, mock.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mock.Invite(ctx, db, ids["invite_only"], author, []string{"guest"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		viewerID string
+		want     []string
+	}{
+		{"author", []string{"code", "invite_only", "unlisted", "closed", "draft", "public"}},
+		{"guest", []string{"invite_only", "closed", "public"}},
+		{"stranger", []string{"closed", "public"}},
+	}
+	for _, tt := range tests {
+		page, err := mock.ListMocks(ctx, db, schemas.MockListRequest{ViewerID: tt.viewerID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(page.Mocks))
+		for i, m := range page.Mocks {
+			got[i] = m.Topic
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("expected %s to list %v, got %v", tt.viewerID, tt.want, got)
+		}
+	}
+
+	// Filtering by status does not bring back what is hidden.
+	page, err := mock.ListMocks(ctx, db, schemas.MockListRequest{Status: entities.MockDraft, ViewerID: "stranger"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Mocks) != 0 {
+		t.Errorf("expected no drafts of other authors, got %v", strings.Join(listedIDs(page.Mocks), ", "))
+	}
+}
@@ -17,6 +17,10 @@ import (
 )
 
 func CreateMock(ctx context.Context, db *sql.DB, mockData schemas.MockCreateRequest) (*entities.Mock, error) {
+	if err := ValidateMock(mockData); err != nil {
+		return nil, err
+	}
+
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
@@ -24,23 +28,64 @@ func CreateMock(ctx context.Context, db *sql.DB, mockData schemas.MockCreateRequ
 	defer tx.Rollback()
 
 	entity := entities.Mock{
-		ID:            uuid.NewString(),
-		Topic:         mockData.Topic,
-		Instructions:  mockData.Instructions,
-		TimeMins:      mockData.TimeMins,
-		AuthorID:      mockData.AuthorID,
+		ID:           uuid.NewString(),
+		Topic:        mockData.Topic,
+		Instructions: mockData.Instructions,
+		TimeMins:     mockData.TimeMins,
+		AuthorID:     mockData.AuthorID,
+		ReviewPolicy: mockData.ReviewPolicy,
+
+		NegativeMarking: 1,
+		FloorAtZero:     mockData.FloorAtZero,
+		PartialCredit:   mockData.PartialCredit,
+
+		ShuffleQuestions: mockData.ShuffleQuestions,
+		ShuffleOptions:   mockData.ShuffleOptions,
+
+		Status:       entities.MockDraft,
+		Visibility:   mockData.Visibility,
+		MaxAttempts:  mockData.MaxAttempts,
+		CooldownMins: mockData.CooldownMins,
+
 		CreatedAt:     time.Now(),
 		LastUpdatedAt: time.Now(),
 	}
 
-	cols := []string{"id", "topic", "instructions", "timeMins", "authorID", "createdAt", "lastUpdatedAt"}
+	if entity.ReviewPolicy == "" {
+		entity.ReviewPolicy = entities.ReviewNever
+	}
+	if mockData.NegativeMarking != nil {
+		entity.NegativeMarking = *mockData.NegativeMarking
+	}
+
+	if entity.Visibility == "" {
+		entity.Visibility = entities.VisibilityPublic
+	}
+	accessCodeHash, err := hashAccessCode(mockData.AccessCode)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := windowLocation(mockData.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	if entity.OpensAt, err = parseWindowTime(mockData.OpensAt, loc); err != nil {
+		return nil, err
+	}
+	if entity.ClosesAt, err = parseWindowTime(mockData.ClosesAt, loc); err != nil {
+		return nil, err
+	}
+	entity.Timezone = mockData.Timezone
+
+	cols := []string{"id", "topic", "instructions", "timeMins", "authorID", "reviewPolicy", "negativeMarking", "floorAtZero", "partialCredit", "status", "visibility", "accessCodeHash", "shuffleQuestions", "shuffleOptions", "maxAttempts", "cooldownMins", "opensAt", "closesAt", "timezone", "createdAt", "lastUpdatedAt"}
 	placeholders := make([]string, len(cols))
 	for i := range placeholders {
 		placeholders[i] = "?"
 	}
 
 	stmt := fmt.Sprintf(`INSERT INTO mock (%s) VALUES (%s)`, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
-	vals := []any{entity.ID, entity.Topic, entity.Instructions, entity.TimeMins, entity.AuthorID, entity.CreatedAt, entity.LastUpdatedAt}
+	vals := []any{entity.ID, entity.Topic, entity.Instructions, entity.TimeMins, entity.AuthorID, entity.ReviewPolicy, entity.NegativeMarking, entity.FloorAtZero, entity.PartialCredit, entity.Status, entity.Visibility, accessCodeHash, entity.ShuffleQuestions, entity.ShuffleOptions, entity.MaxAttempts, entity.CooldownMins, entity.OpensAt, entity.ClosesAt, entity.Timezone, entity.CreatedAt, entity.LastUpdatedAt}
 
 	if _, err := tx.ExecContext(ctx, stmt, vals...); err != nil {
 		return nil, data.SQLiteErrorComparator(err)
@@ -51,6 +96,18 @@ func CreateMock(ctx context.Context, db *sql.DB, mockData schemas.MockCreateRequ
 		return nil, err
 	}
 
+	if _, err := replaceBankLinks(ctx, tx, entity.ID, entity.AuthorID, mockData.BankQuestions, entity.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := replacePools(ctx, tx, entity.ID, mockData.Pools); err != nil {
+		return nil, err
+	}
+
+	if err := replaceMockTags(ctx, tx, entity.ID, mockData.Tags); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -60,34 +117,38 @@ func CreateMock(ctx context.Context, db *sql.DB, mockData schemas.MockCreateRequ
 
 type FullMock struct {
 	entities.Mock
-	Questions []FullMockQuestion `json:"questions"`
+	Tags      []string            `json:"tags"`
+	Pools     []entities.MockPool `json:"pools,omitempty"`
+	Questions []FullMockQuestion  `json:"questions"`
 }
 
+// Questions linked from the bank carry the bank question's ID and content,
+// with the points and position of the link.
 type FullMockQuestion struct {
 	entities.MockQuestion
-	Options []entities.MockOption `json:"options"`
+	Options  []entities.MockOption `json:"options"`
+	FromBank bool                  `json:"from_bank,omitempty"`
 }
 
-func GetMock(ctx context.Context, db *sql.DB, id string) (*FullMock, error) {
-	var mock entities.Mock
-	mockStmt := `
-        SELECT id, topic, instructions, timeMins, authorID, createdAt, lastUpdatedAt
-        FROM mock
-        WHERE id = ?
-    `
+// Satisfied by both *sql.DB and *sql.Tx, so reads can join a transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
 
-	var createdAtString, lastUpdatedAtString string 
+// Fetch only the "mock" row, without its questions and options.
+func GetMockMeta(ctx context.Context, db *sql.DB, id string) (*entities.Mock, error) {
+	return getMockMeta(ctx, db, id)
+}
 
-	err := db.QueryRowContext(ctx, mockStmt, id).Scan(
-		&mock.ID,
-		&mock.Topic,
-		&mock.Instructions,
-		&mock.TimeMins,
-		&mock.AuthorID,
-		&createdAtString,
-		&lastUpdatedAtString,
-	)
+func GetMock(ctx context.Context, db *sql.DB, id string) (*FullMock, error) {
+	return getMock(ctx, db, id)
+}
+
+func getMockMeta(ctx context.Context, db querier, id string) (*entities.Mock, error) {
+	mockStmt := fmt.Sprintf(`SELECT %s FROM mock WHERE id = ?`, strings.Join(mockColumns, ", "))
 
+	mock, err := scanMock(db.QueryRowContext(ctx, mockStmt, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.NewError(err, errs.DataErrorType, errs.ErrNotFound)
@@ -95,18 +156,28 @@ func GetMock(ctx context.Context, db *sql.DB, id string) (*FullMock, error) {
 		return nil, data.SQLiteErrorComparator(err)
 	}
 
-	createdAt, _ := utils.ParseTime(createdAtString)
-	lastUpdatedAt, _ := utils.ParseTime(lastUpdatedAtString)
+	return mock, nil
+}
 
-	mock.CreatedAt = *createdAt
-	mock.LastUpdatedAt = *lastUpdatedAt
+func getMock(ctx context.Context, db querier, id string) (*FullMock, error) {
+	mock, err := getMockMeta(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
 
+	// Own questions come before bank questions sharing their position.
 	qStmt := `
-        SELECT id, problem, points, mockID, createdAt, lastUpdatedAt
+        SELECT id, type, problem, points, correctOptionID, explanation, negativePoints, numericAnswer, tolerance, position, pool, mockID, createdAt, lastUpdatedAt, 0 AS fromBank
         FROM mockQuestion
         WHERE mockID = ?
+        UNION ALL
+        SELECT q.id, q.type, q.problem, l.points, q.correctOptionID, q.explanation, q.negativePoints, q.numericAnswer, q.tolerance, l.position, l.pool, l.mockID, q.createdAt, q.lastUpdatedAt, 1 AS fromBank
+        FROM mockBankQuestion l
+        JOIN bankQuestion q ON q.id = l.questionID
+        WHERE l.mockID = ?
+        ORDER BY position, fromBank, createdAt
     `
-	rows, err := db.QueryContext(ctx, qStmt, mock.ID)
+	rows, err := db.QueryContext(ctx, qStmt, mock.ID, mock.ID)
 	if err != nil {
 		return nil, data.SQLiteErrorComparator(err)
 	}
@@ -115,16 +186,27 @@ func GetMock(ctx context.Context, db *sql.DB, id string) (*FullMock, error) {
 	var fullQuestions []FullMockQuestion
 	for rows.Next() {
 		var q entities.MockQuestion
+		var fromBank bool
 
-		var qCreatedAtStr, qLastUpdatedAtStr string 
+		var qCreatedAtStr, qLastUpdatedAtStr string
+		var negativePoints, numericAnswer sql.NullFloat64
 
 		if err := rows.Scan(
 			&q.ID,
+			&q.Type,
 			&q.Problem,
 			&q.Points,
+			&q.CorrectOptionID,
+			&q.Explanation,
+			&negativePoints,
+			&numericAnswer,
+			&q.Tolerance,
+			&q.Position,
+			&q.Pool,
 			&q.MockID,
 			&qCreatedAtStr,
 			&qLastUpdatedAtStr,
+			&fromBank,
 		); err != nil {
 			return nil, err
 		}
@@ -132,64 +214,121 @@ func GetMock(ctx context.Context, db *sql.DB, id string) (*FullMock, error) {
 		qCreatedAt, _ := utils.ParseTime(qCreatedAtStr)
 		qLastUpdatedAt, _ := utils.ParseTime(qLastUpdatedAtStr)
 
+		if negativePoints.Valid {
+			q.NegativePoints = &negativePoints.Float64
+		}
+		if numericAnswer.Valid {
+			q.NumericAnswer = &numericAnswer.Float64
+		}
+
 		q.CreatedAt = *qCreatedAt
 		q.LastUpdatedAt = *qLastUpdatedAt
 
-		optStmt := `
-            SELECT id, number, option, questionID, createdAt, lastUpdatedAt
-            FROM mockOption
-            WHERE questionID = ?
-        `
-		optRows, err := db.QueryContext(ctx, optStmt, q.ID)
-		if err != nil {
-			return nil, data.SQLiteErrorComparator(err)
-		}
+		fullQuestions = append(fullQuestions, FullMockQuestion{MockQuestion: q, FromBank: fromBank})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
-		var options []entities.MockOption
-		for optRows.Next() {
-			var opt entities.MockOption
-
-			var optCreatedAtStr, optLastUpdatedAtStr string 
-
-			if err := optRows.Scan(
-				&opt.ID,
-				&opt.Number,
-				&opt.Option,
-				&opt.QuestionID,
-				&optCreatedAtStr,
-				&optLastUpdatedAtStr,
-			); err != nil {
-				optRows.Close()
-				return nil, err
-			}
+	options, err := getMockOptions(ctx, db, mock.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fullQuestions {
+		fullQuestions[i].Options = options[fullQuestions[i].ID]
+	}
+
+	tags, err := getMockTags(ctx, db, []string{mock.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	pools, err := getMockPools(ctx, db, mock.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FullMock{
+		Mock:      *mock,
+		Tags:      tags[mock.ID],
+		Pools:     pools,
+		Questions: fullQuestions,
+	}, nil
+}
+
+// Load the options of every question of a mock, bank questions included, in one query.
+// [K : questionID] [V : options, by number]
+func getMockOptions(ctx context.Context, db querier, mockID string) (map[string][]entities.MockOption, error) {
+	optStmt := `
+        SELECT o.id, o.number, o.option, o.isCorrect, o.match, o.matchID, o.questionID, o.createdAt, o.lastUpdatedAt
+        FROM mockOption o
+        JOIN mockQuestion q ON q.id = o.questionID
+        WHERE q.mockID = ?
+        UNION ALL
+        SELECT o.id, o.number, o.option, o.isCorrect, o.match, o.matchID, o.questionID, o.createdAt, o.lastUpdatedAt
+        FROM bankOption o
+        JOIN mockBankQuestion l ON l.questionID = o.questionID
+        WHERE l.mockID = ?
+        ORDER BY questionID, number
+    `
+	rows, err := db.QueryContext(ctx, optStmt, mockID, mockID)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	return scanOptions(rows)
+}
 
-			optCreatedAt, _ := utils.ParseTime(optCreatedAtStr)
-			optLastUpdatedAt, _ := utils.ParseTime(optLastUpdatedAtStr)
+// [K : questionID] [V : options, in the order of the rows]
+func scanOptions(rows *sql.Rows) (map[string][]entities.MockOption, error) {
+	options := make(map[string][]entities.MockOption)
+	for rows.Next() {
+		var opt entities.MockOption
 
-			opt.CreatedAt = *optCreatedAt
-			opt.LastUpdatedAt = *optLastUpdatedAt
+		var optCreatedAtStr, optLastUpdatedAtStr string
 
-			options = append(options, opt)
+		if err := rows.Scan(
+			&opt.ID,
+			&opt.Number,
+			&opt.Option,
+			&opt.IsCorrect,
+			&opt.Match,
+			&opt.MatchID,
+			&opt.QuestionID,
+			&optCreatedAtStr,
+			&optLastUpdatedAtStr,
+		); err != nil {
+			return nil, err
 		}
-		optRows.Close()
 
-		fullQuestions = append(fullQuestions, FullMockQuestion{
-			MockQuestion: q,
-			Options:      options,
-		})
+		optCreatedAt, _ := utils.ParseTime(optCreatedAtStr)
+		optLastUpdatedAt, _ := utils.ParseTime(optLastUpdatedAtStr)
+
+		opt.CreatedAt = *optCreatedAt
+		opt.LastUpdatedAt = *optLastUpdatedAt
+
+		options[opt.QuestionID] = append(options[opt.QuestionID], opt)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return &FullMock{
-		Mock:      mock,
-		Questions: fullQuestions,
-	}, nil
+	return options, nil
 }
 
 func insertMockQuestions(ctx context.Context, tx *sql.Tx, mockData schemas.MockCreateRequest, entity entities.Mock) error {
-	mockQCols := []string{"id", "problem", "points", "correctOptionID", "mockID", "createdAt", "lastUpdatedAt"}
+	for position, q := range mockData.Questions {
+		if _, err := insertMockQuestion(ctx, tx, entity.ID, position, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertMockQuestion(ctx context.Context, tx *sql.Tx, mockID string, position int, q schemas.MockQuestionSchema) (*entities.MockQuestion, error) {
+	mockQCols := []string{"id", "type", "problem", "points", "correctOptionID", "explanation", "negativePoints", "numericAnswer", "tolerance", "position", "pool", "mockID", "createdAt", "lastUpdatedAt"}
 	mockQPlaceholders := make([]string, len(mockQCols))
 	for i := range mockQPlaceholders {
 		mockQPlaceholders[i] = "?"
@@ -197,45 +336,159 @@ func insertMockQuestions(ctx context.Context, tx *sql.Tx, mockData schemas.MockC
 
 	mockQStmt := fmt.Sprintf(`INSERT INTO mockQuestion (%s) VALUES (%s)`, strings.Join(mockQCols, ", "), strings.Join(mockQPlaceholders, ", "))
 
-	for _, q := range mockData.Questions {
-		mockQ := entities.MockQuestion{
-			ID:              uuid.NewString(),
-			Problem:         q.Problem,
-			Points:          q.Points,
-			CorrectOptionID: q.CorrectOptionID,
-			MockID:          entity.ID,
-			CreatedAt:       time.Now(),
-			LastUpdatedAt:   time.Now(),
-		}
+	mockQ := entities.MockQuestion{
+		ID:             uuid.NewString(),
+		Type:           q.Type,
+		Problem:        q.Problem,
+		Points:         q.Points,
+		Explanation:    q.Explanation,
+		NegativePoints: q.NegativePoints,
+		NumericAnswer:  q.NumericAnswer,
+		Tolerance:      q.Tolerance,
+		Position:       position,
+		Pool:           q.Pool,
+		MockID:         mockID,
+		CreatedAt:      time.Now(),
+		LastUpdatedAt:  time.Now(),
+	}
 
-		mockQVals := []any{mockQ.ID, mockQ.Problem, mockQ.Points, mockQ.CorrectOptionID, mockQ.MockID, mockQ.CreatedAt, mockQ.LastUpdatedAt}
-		if _, err := tx.ExecContext(ctx, mockQStmt, mockQVals...); err != nil {
-			return data.SQLiteErrorComparator(err)
-		}
+	if mockQ.Type == "" {
+		mockQ.Type = entities.QuestionSingleChoice
+	}
 
-		if err := insertMockOptions(ctx, q, mockQ.ID, tx); err != nil {
-			return err
+	// Option IDs are generated here, so the correct option is resolved before anything is written.
+	options := make([]entities.MockOption, len(q.Options))
+	for i, opt := range q.Options {
+		options[i] = newMockOption(mockQ.ID, opt)
+	}
+
+	correctOptionID, err := markCorrect(mockQ.Type, q.CorrectOptionNumber, options)
+	if err != nil {
+		return nil, err
+	}
+	mockQ.CorrectOptionID = correctOptionID
+
+	mockQVals := []any{mockQ.ID, mockQ.Type, mockQ.Problem, mockQ.Points, mockQ.CorrectOptionID, mockQ.Explanation, mockQ.NegativePoints, mockQ.NumericAnswer, mockQ.Tolerance, mockQ.Position, mockQ.Pool, mockQ.MockID, mockQ.CreatedAt, mockQ.LastUpdatedAt}
+	if _, err := tx.ExecContext(ctx, mockQStmt, mockQVals...); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	for _, opt := range options {
+		if err := insertOption(ctx, tx, mockOptionTable, opt); err != nil {
+			return nil, err
 		}
 	}
+	return &mockQ, nil
+}
+
+func newMockOption(questionID string, opt schemas.MockOptionSchema) entities.MockOption {
+	return entities.MockOption{
+		ID:            uuid.NewString(),
+		Number:        opt.Number,
+		Option:        opt.Option,
+		IsCorrect:     opt.IsCorrect,
+		Match:         opt.Match,
+		MatchID:       newMatchID(opt.Match),
+		QuestionID:    questionID,
+		CreatedAt:     time.Now(),
+		LastUpdatedAt: time.Now(),
+	}
+}
+
+func newMatchID(match string) string {
+	if match == "" {
+		return ""
+	}
+	return uuid.NewString()
+}
+
+// Options of mock questions and of bank questions share a shape, only their table differs.
+const (
+	mockOptionTable = "mockOption"
+	bankOptionTable = "bankOption"
+)
+
+func insertOption(ctx context.Context, tx *sql.Tx, table string, option entities.MockOption) error {
+	stmt := fmt.Sprintf(`INSERT INTO %s (id, number, option, isCorrect, match, matchID, questionID, createdAt, lastUpdatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, table)
+
+	vals := []any{option.ID, option.Number, option.Option, option.IsCorrect, option.Match, option.MatchID, option.QuestionID, option.CreatedAt, option.LastUpdatedAt}
+	if _, err := tx.ExecContext(ctx, stmt, vals...); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
 	return nil
 }
 
-func insertMockOptions(ctx context.Context, q schemas.MockQuestionSchema, questionID string, tx *sql.Tx) error {
-	stmt := `INSERT INTO mockOption (id, number, option, questionID, createdAt, lastUpdatedAt) VALUES (?, ?, ?, ?, ?, ?)`
+// Single-answer questions keep the ID of their correct option in CorrectOptionID.
+func isSingleAnswer(typ string) bool {
+	return typ == "" || typ == entities.QuestionSingleChoice || typ == entities.QuestionTrueFalse
+}
+
+// Flag the correct option of a single-answer question and return its ID.
+// Other question types keep their flags and have no correct option ID.
+func markCorrect(typ string, correctOptionNumber int, options []entities.MockOption) (string, error) {
+	if !isSingleAnswer(typ) {
+		return "", nil
+	}
+
+	numbers, flags := optionNumbers(options)
+	n, err := correctNumber(correctOptionNumber, numbers, flags)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range options {
+		options[i].IsCorrect = options[i].Number == n
+	}
+	return optionWithNumber(options, n), nil
+}
+
+/*
+* Number of the correct option of a single-answer question: the one named by
+* correctOptionNumber, or else the only option flagged is_correct. Either way
+* it must match exactly one option.
+ */
+func correctNumber(correctOptionNumber int, numbers []int, flags []bool) (int, error) {
+	n := correctOptionNumber
+	if n == 0 {
+		flagged := 0
+		for i, isCorrect := range flags {
+			if isCorrect {
+				n = numbers[i]
+				flagged++
+			}
+		}
+		if flagged != 1 {
+			return 0, errs.NewError(fmt.Errorf("exactly one option must be marked correct, got %d", flagged), errs.DataErrorType, errs.ErrDataIllegal)
+		}
+	}
 
-	for _, opt := range q.Options {
-		option := entities.MockOption{
-			ID:            uuid.NewString(),
-			Number:        opt.Number,
-			Option:        opt.Option,
-			QuestionID:    questionID,
-			CreatedAt:     time.Now(),
-			LastUpdatedAt: time.Now(),
+	matches := 0
+	for _, number := range numbers {
+		if number == n {
+			matches++
 		}
-		vals := []any{option.ID, option.Number, option.Option, option.QuestionID, option.CreatedAt, option.LastUpdatedAt}
-		if _, err := tx.ExecContext(ctx, stmt, vals...); err != nil {
-			return data.SQLiteErrorComparator(err)
+	}
+	if matches != 1 {
+		return 0, errs.NewError(fmt.Errorf("correct option %d must match exactly one option, got %d", n, matches), errs.DataErrorType, errs.ErrDataIllegal)
+	}
+	return n, nil
+}
+
+func optionNumbers(options []entities.MockOption) ([]int, []bool) {
+	numbers := make([]int, len(options))
+	flags := make([]bool, len(options))
+	for i, opt := range options {
+		numbers[i] = opt.Number
+		flags[i] = opt.IsCorrect
+	}
+	return numbers, flags
+}
+
+func optionWithNumber(options []entities.MockOption, number int) string {
+	for _, opt := range options {
+		if opt.Number == number {
+			return opt.ID
 		}
 	}
-	return nil
+	return ""
 }
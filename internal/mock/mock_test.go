@@ -0,0 +1,489 @@
+package mock_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+func createTestMock(tb testing.TB, db *sql.DB, questions int) string {
+	testutil.CreateUsers(tb, db, "author")
+	req := schemas.MockCreateRequest{Topic: "Benchmark", Instructions: "Answer everything", TimeMins: 60, AuthorID: "author"}
+	for i := range questions {
+		req.Questions = append(req.Questions, schemas.MockQuestionSchema{
+			Problem:             fmt.Sprintf("Question %d", i),
+			Points:              1,
+			CorrectOptionNumber: 4,
+			Options: []schemas.MockOptionSchema{
+				{Number: 4, Option: "d"}, {Number: 2, Option: "b"}, {Number: 3, Option: "c"}, {Number: 1, Option: "a"},
+			},
+		})
+	}
+
+	m, err := mock.CreateMock(context.Background(), db, req)
+	if err != nil {
+		tb.Fatalf("failed to create mock :: %v", err)
+	}
+	return m.ID
+}
+
+func TestGetMockOrdering(t *testing.T) {
+	db := testutil.NewDB(t)
+	id := createTestMock(t, db, 5)
+
+	m, err := mock.GetMock(context.Background(), db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Questions) != 5 {
+		t.Fatalf("expected 5 questions, got %d", len(m.Questions))
+	}
+
+	for i, q := range m.Questions {
+		if q.Problem != fmt.Sprintf("Question %d", i) {
+			t.Errorf("expected question %d at position %d, got %q", i, i, q.Problem)
+		}
+		if len(q.Options) != 4 {
+			t.Fatalf("expected 4 options on %s, got %d", q.ID, len(q.Options))
+		}
+		for j, opt := range q.Options {
+			if opt.Number != j+1 || opt.QuestionID != q.ID {
+				t.Errorf("unexpected option %d of %s :: %+v", j, q.ID, opt)
+			}
+		}
+		if q.CorrectOptionID != q.Options[3].ID {
+			t.Errorf("expected option 4 to be correct on %s", q.ID)
+		}
+	}
+}
+
+func BenchmarkGetMock(b *testing.B) {
+	db := testutil.NewDB(b)
+	id := createTestMock(b, db, 200)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, err := mock.GetMock(ctx, db, id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	id := createTestMock(t, db, 2)
+
+	cache := mock.NewCache(db, nil)
+
+	first, err := cache.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := cache.Get(ctx, id); second != first {
+		t.Fatal("expected the second read to be served from the cache")
+	}
+
+	topic := "Renamed"
+	if _, err := mock.UpdateMock(ctx, db, id, "author", schemas.MockUpdateRequest{Topic: &topic}, mock.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Invalidate(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := cache.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Topic != topic || len(updated.Questions) != 2 {
+		t.Fatalf("expected the updated mock after invalidation, got %+v", updated.Mock)
+	}
+
+	if _, err := cache.Get(ctx, "missing"); err == nil {
+		t.Fatal("expected an error for a missing mock")
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	id := createTestMock(t, db, 1)
+
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+	stranger := &entities.User{ID: "stranger", Role: entities.RoleUser}
+
+	listed := func(viewerID string) int {
+		page, err := mock.ListMocks(ctx, db, schemas.MockListRequest{ViewerID: viewerID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(page.Mocks)
+	}
+
+	m, err := mock.GetMockMeta(ctx, db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Status != entities.MockDraft {
+		t.Fatalf("expected a new mock to be a draft, got %q", m.Status)
+	}
+	if listed("stranger") != 0 || listed("author") != 1 {
+		t.Fatal("expected the draft to be listed for its author only")
+	}
+
+	steps := []struct {
+		user   *entities.User
+		status string
+		code   int // -1 when the step succeeds.
+	}{
+		{author, entities.MockClosed, errs.ErrConflict},
+		{stranger, entities.MockPublished, errs.ErrForbidden},
+		{author, entities.MockPublished, -1},
+		{author, entities.MockDraft, errs.ErrConflict},
+		{author, entities.MockClosed, -1},
+		{author, entities.MockPublished, -1},
+	}
+	for i, step := range steps {
+		_, err := mock.SetStatus(ctx, db, id, step.user, step.status)
+		if step.code == -1 {
+			if err != nil {
+				t.Fatalf("step %d :: expected %s to succeed, got %v", i, step.status, err)
+			}
+			continue
+		}
+		if !errors.Is(err, errs.Error{Code: step.code, Type: errs.DataErrorType.String()}) {
+			t.Fatalf("step %d :: expected error code %d, got %v", i, step.code, err)
+		}
+	}
+
+	if listed("stranger") != 1 {
+		t.Fatal("expected the published mock to be listed")
+	}
+
+	if err := mock.DeleteMock(ctx, db, id, author, mock.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mock.SetStatus(ctx, db, id, author, entities.MockPublished); err == nil {
+		t.Fatal("expected an archived mock to stay archived")
+	}
+}
+
+func TestAvailabilityWindow(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author")
+
+	req := schemas.MockCreateRequest{
+		Topic: "Scheduled", Instructions: "i", TimeMins: 60, AuthorID: "author",
+		OpensAt: "2030-01-01T09:00", ClosesAt: "2030-01-01T12:00:00+05:30", Timezone: "Asia/Kolkata",
+		Questions: []schemas.MockQuestionSchema{{Problem: "p", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}}}},
+	}
+	created, err := mock.CreateMock(ctx, db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mock.GetMockMeta(ctx, db, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opens := time.Date(2030, 1, 1, 3, 30, 0, 0, time.UTC)
+	if m.OpensAt == nil || !m.OpensAt.Equal(opens) || m.ClosesAt == nil || !m.ClosesAt.Equal(opens.Add(3*time.Hour)) {
+		t.Fatalf("expected the window to be read in Asia/Kolkata, got %v - %v", m.OpensAt, m.ClosesAt)
+	}
+	if m.OpenAt(opens.Add(-time.Second)) || !m.OpenAt(opens) || m.OpenAt(*m.ClosesAt) {
+		t.Fatal("expected the window to include opens_at and exclude closes_at")
+	}
+
+	early := "2030-01-01T08:00"
+	_, err = mock.UpdateMock(ctx, db, created.ID, "author", schemas.MockUpdateRequest{ClosesAt: &early}, mock.UpdateOptions{})
+	if !errors.Is(err, errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}) {
+		t.Fatalf("expected a window closing before it opens to be refused, got %v", err)
+	}
+
+	open := ""
+	updated, err := mock.UpdateMock(ctx, db, created.ID, "author", schemas.MockUpdateRequest{OpensAt: &open}, mock.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.OpensAt != nil || updated.ClosesAt == nil {
+		t.Fatalf("expected only opens_at to be cleared, got %v - %v", updated.OpensAt, updated.ClosesAt)
+	}
+
+	req.Timezone = "Mars/Olympus"
+	req.OpensAt = "tomorrow"
+	err = mock.ValidateMock(req)
+	for _, path := range []string{"timezone", "opens_at"} {
+		if err == nil || !strings.Contains(err.Error(), "[FailedField : "+path+"]") {
+			t.Errorf("expected a problem at %s, got %v", path, err)
+		}
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author", "candidate")
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+
+	req := schemas.MockCreateRequest{
+		Topic: "Cohort assessment", Instructions: "i", TimeMins: 30, AuthorID: author.ID,
+		Visibility: entities.VisibilityCode, AccessCode: "cohort-7",
+		Questions: []schemas.MockQuestionSchema{{Problem: "p", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}}}},
+	}
+	created, err := mock.CreateMock(ctx, db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mock.SetStatus(ctx, db, created.ID, author, entities.MockPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	forbidden := errs.Error{Code: errs.ErrForbidden, Type: errs.DataErrorType.String()}
+	check := func(userID string, code string) error {
+		m, err := mock.GetMockMeta(ctx, db, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mock.CheckAccess(ctx, db, m, userID, code)
+	}
+	listed := func(viewerID string) int {
+		page, err := mock.ListMocks(ctx, db, schemas.MockListRequest{ViewerID: viewerID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(page.Mocks)
+	}
+
+	if err := check("author", ""); err != nil {
+		t.Fatalf("expected the author to need no code, got %v", err)
+	}
+	if err := check("candidate", ""); !errors.Is(err, forbidden) {
+		t.Fatalf("expected a missing code to be refused, got %v", err)
+	}
+	if err := check("candidate", "cohort-8"); !errors.Is(err, forbidden) {
+		t.Fatalf("expected a wrong code to be refused, got %v", err)
+	}
+	if err := check("candidate", "cohort-7"); err != nil {
+		t.Fatalf("expected the right code to be accepted, got %v", err)
+	}
+	if listed("candidate") != 0 || listed("author") != 1 {
+		t.Fatal("expected the protected mock to be listed for its author only")
+	}
+
+	inviteOnly := entities.VisibilityInviteOnly
+	if _, err := mock.UpdateMock(ctx, db, created.ID, author.ID, schemas.MockUpdateRequest{Visibility: &inviteOnly}, mock.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := check("candidate", "cohort-7"); !errors.Is(err, mock.ErrInviteOnly) {
+		t.Fatalf("expected the code to stop working on an invite-only mock, got %v", err)
+	}
+
+	if err := mock.Invite(ctx, db, created.ID, &entities.User{ID: "candidate", Role: entities.RoleUser}, []string{"candidate"}); !errors.Is(err, forbidden) {
+		t.Fatalf("expected only the author to invite, got %v", err)
+	}
+	for range 2 {
+		if err := mock.Invite(ctx, db, created.ID, author, []string{"candidate"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mock.Invite(ctx, db, created.ID, author, []string{"nobody"}); !errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.SQLErrorType.String()}) {
+		t.Errorf("expected inviting a user who does not exist to be not found, got %v", err)
+	}
+	invitations, err := mock.ListInvitations(ctx, db, created.ID, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invitations) != 1 {
+		t.Fatalf("expected inviting twice to keep one invitation, got %d", len(invitations))
+	}
+	if err := check("candidate", ""); err != nil {
+		t.Fatalf("expected the invited user to get in, got %v", err)
+	}
+	if listed("candidate") != 1 {
+		t.Fatal("expected the invited user to see the mock listed")
+	}
+
+	if err := mock.Uninvite(ctx, db, created.ID, author, "candidate"); err != nil {
+		t.Fatal(err)
+	}
+	if err := check("candidate", ""); !errors.Is(err, mock.ErrInviteOnly) {
+		t.Fatalf("expected the withdrawn invitation to be refused, got %v", err)
+	}
+
+	unlisted := entities.VisibilityUnlisted
+	if _, err := mock.UpdateMock(ctx, db, created.ID, author.ID, schemas.MockUpdateRequest{Visibility: &unlisted}, mock.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := check("candidate", ""); err != nil || listed("candidate") != 0 {
+		t.Fatalf("expected an unlisted mock to open by ID but stay off the list, got %v", err)
+	}
+
+	code := entities.VisibilityCode
+	none := ""
+	_, err = mock.UpdateMock(ctx, db, created.ID, author.ID, schemas.MockUpdateRequest{Visibility: &code, AccessCode: &none}, mock.UpdateOptions{})
+	if !errors.Is(err, errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}) {
+		t.Fatalf("expected code visibility without a code to be refused, got %v", err)
+	}
+}
+
+func TestBankQuestions(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author")
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+	unchanged := mock.UpdateOptions{}
+	conflict := errs.Error{Code: errs.ErrConflict, Type: errs.DataErrorType.String()}
+	illegal := errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}
+
+	question := schemas.MockQuestionSchema{Problem: "2 + 2", Points: 1, CorrectOptionNumber: 2, Options: []schemas.MockOptionSchema{{Number: 1, Option: "3"}, {Number: 2, Option: "4"}}}
+	banked, err := mock.CreateBankQuestion(ctx, db, author.ID, question)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CreateUsers(t, db, "someone else")
+	other, err := mock.CreateBankQuestion(ctx, db, "someone else", question)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := schemas.MockCreateRequest{
+		Topic: "Arithmetic", Instructions: "i", TimeMins: 30, AuthorID: author.ID,
+		Questions:     []schemas.MockQuestionSchema{{Problem: "1 + 1", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "2"}, {Number: 2, Option: "3"}}}},
+		BankQuestions: []schemas.MockBankQuestionSchema{{QuestionID: banked.ID, Points: 3, Position: 1}},
+	}
+	created, err := mock.CreateMock(ctx, db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.BankQuestions = []schemas.MockBankQuestionSchema{{QuestionID: other.ID}}
+	if _, err := mock.CreateMock(ctx, db, req); !errors.Is(err, illegal) {
+		t.Fatalf("expected another author's question to be refused, got %v", err)
+	}
+
+	m, err := mock.GetMock(ctx, db, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Questions) != 2 || m.Questions[0].FromBank || !m.Questions[1].FromBank {
+		t.Fatalf("expected the own question then the bank question, got %+v", m.Questions)
+	}
+	linked := m.Questions[1]
+	if linked.ID != banked.ID || linked.Points != 3 || linked.MockID != created.ID || len(linked.Options) != 2 || linked.CorrectOptionID != banked.CorrectOptionID {
+		t.Fatalf("expected the bank question worth 3 points, got %+v", linked)
+	}
+
+	// Rewording reaches every linked mock without touching how it is graded.
+	update := schemas.MockQuestionUpdateSchema{Problem: "Two plus two", Points: 1, CorrectOptionNumber: 2}
+	for _, opt := range banked.Options {
+		update.Options = append(update.Options, schemas.MockOptionUpdateSchema{ID: opt.ID, Number: opt.Number, Option: opt.Option})
+	}
+	updated, err := mock.UpdateBankQuestion(ctx, db, banked.ID, author.ID, update, unchanged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.MockIDs) != 1 || updated.MockIDs[0] != created.ID {
+		t.Fatalf("expected the question to know its mock, got %v", updated.MockIDs)
+	}
+	m, err = mock.GetMock(ctx, db, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Questions[1].Problem != "Two plus two" || !m.LastUpdatedAt.After(created.LastUpdatedAt) {
+		t.Fatal("expected the rewording to reach the mock and bump its version")
+	}
+
+	update.CorrectOptionNumber = 1
+	if _, err := mock.UpdateBankQuestion(ctx, db, banked.ID, author.ID, update, unchanged); !errors.Is(err, mock.ErrBankStructuralChange) {
+		t.Fatalf("expected a new answer key to be structural, got %v", err)
+	}
+	if _, err := mock.UpdateBankQuestion(ctx, db, banked.ID, author.ID, update, mock.UpdateOptions{AllowStructural: true}); !errors.Is(err, mock.ErrBankStructuralChange) {
+		t.Fatalf("expected a structural change to be refused for a mock that was not checked, got %v", err)
+	}
+	if _, err := mock.UpdateBankQuestion(ctx, db, banked.ID, author.ID, update, mock.UpdateOptions{AllowStructural: true, LinkedMocks: []string{created.ID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.DeleteBankQuestion(ctx, db, banked.ID, author); !errors.Is(err, conflict) {
+		t.Fatalf("expected a linked question to be kept, got %v", err)
+	}
+
+	none := []schemas.MockBankQuestionSchema{}
+	if _, err := mock.UpdateMock(ctx, db, created.ID, author.ID, schemas.MockUpdateRequest{BankQuestions: &none}, unchanged); !errors.Is(err, mock.ErrStructuralChange) {
+		t.Fatalf("expected unlinking to be structural, got %v", err)
+	}
+	if _, err := mock.UpdateMock(ctx, db, created.ID, author.ID, schemas.MockUpdateRequest{BankQuestions: &none}, mock.UpdateOptions{AllowStructural: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.DeleteBankQuestion(ctx, db, banked.ID, author); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mock.GetBankQuestion(ctx, db, banked.ID, author); !errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.DataErrorType.String()}) {
+		t.Fatalf("expected the question to be gone, got %v", err)
+	}
+}
+
+func TestPools(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author")
+	illegal := errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}
+
+	question := func(problem string, pool string) schemas.MockQuestionSchema {
+		return schemas.MockQuestionSchema{Problem: problem, Points: 1, CorrectOptionNumber: 1, Pool: pool, Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}}}
+	}
+	req := schemas.MockCreateRequest{
+		Topic: "Pooled", Instructions: "i", TimeMins: 30, AuthorID: "author",
+		Pools:     []schemas.MockPoolSchema{{Name: "easy", Draw: 2}, {Name: "easy", Draw: 1}},
+		Questions: []schemas.MockQuestionSchema{question("q1", "easy"), question("q2", "hard")},
+	}
+
+	err := mock.ValidateMock(req)
+	for _, path := range []string{"pools[1].name", "pools[0].draw", "questions[1].pool"} {
+		if err == nil || !strings.Contains(err.Error(), "[FailedField : "+path+"]") {
+			t.Errorf("expected a problem at %s, got %v", path, err)
+		}
+	}
+
+	req.Pools = []schemas.MockPoolSchema{{Name: "easy", Draw: 1}}
+	req.Questions = []schemas.MockQuestionSchema{question("q1", "easy"), question("q2", "easy"), question("q3", "")}
+	created, err := mock.CreateMock(ctx, db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mock.GetMock(ctx, db, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Pools) != 1 || m.Pools[0].Draw != 1 || m.Questions[0].Pool != "easy" || m.Questions[2].Pool != "" {
+		t.Fatalf("expected the pool and its questions to be stored, got %+v", m)
+	}
+
+	// Dropping the pool would leave its questions pointing nowhere.
+	none := []schemas.MockPoolSchema{}
+	if _, err := mock.UpdateMock(ctx, db, created.ID, "author", schemas.MockUpdateRequest{Pools: &none}, mock.UpdateOptions{}); !errors.Is(err, illegal) {
+		t.Fatalf("expected the update to be refused, got %v", err)
+	}
+
+	more := []schemas.MockPoolSchema{{Name: "easy", Draw: 2}}
+	updated, err := mock.UpdateMock(ctx, db, created.ID, "author", schemas.MockUpdateRequest{Pools: &more}, mock.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Pools[0].Draw != 2 {
+		t.Fatalf("expected the pool to draw 2, got %+v", updated.Pools)
+	}
+}
@@ -0,0 +1,106 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/schemas"
+)
+
+// The pool a question is in, with the path reported when it is wrong.
+type poolRef struct {
+	path string
+	pool string
+}
+
+func (p *problems) checkPoolNames(pools []schemas.MockPoolSchema) {
+	nameAt := make(map[string]int, len(pools))
+	for i, pool := range pools {
+		if first, ok := nameAt[pool.Name]; ok {
+			p.add(fmt.Sprintf("pools[%d].name", i), fmt.Sprintf("unique=pools[%d]", first), pool.Name)
+		} else {
+			nameAt[pool.Name] = i
+		}
+	}
+}
+
+// Every pool a question names must be declared, and hold at least as many questions as it draws.
+func (p *problems) checkPoolRefs(pools []schemas.MockPoolSchema, refs []poolRef) {
+	declared := make(map[string]bool, len(pools))
+	for _, pool := range pools {
+		declared[pool.Name] = true
+	}
+
+	// [K : pool name] [V : questions in it]
+	counts := make(map[string]int, len(pools))
+	for _, ref := range refs {
+		if ref.pool == "" {
+			continue
+		}
+		if !declared[ref.pool] {
+			p.add(ref.path, "exists=pools", ref.pool)
+			continue
+		}
+		counts[ref.pool]++
+	}
+
+	for i, pool := range pools {
+		if counts[pool.Name] < pool.Draw {
+			p.add(fmt.Sprintf("pools[%d].draw", i), fmt.Sprintf("max=%d", counts[pool.Name]), pool.Draw)
+		}
+	}
+}
+
+// Pools of a mock as it stands after an update, own and bank questions merged in their order.
+func checkMockPools(m *FullMock) error {
+	pools := make([]schemas.MockPoolSchema, len(m.Pools))
+	for i, pool := range m.Pools {
+		pools[i] = schemas.MockPoolSchema{Name: pool.Name, Draw: pool.Draw}
+	}
+
+	refs := make([]poolRef, len(m.Questions))
+	for i, q := range m.Questions {
+		refs[i] = poolRef{path: fmt.Sprintf("questions[%d].pool", i), pool: q.Pool}
+	}
+
+	var p problems
+	p.checkPoolRefs(pools, refs)
+	return p.err()
+}
+
+func replacePools(ctx context.Context, tx *sql.Tx, mockID string, pools []schemas.MockPoolSchema) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mockPool WHERE mockID = ?`, mockID); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+
+	for _, pool := range pools {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO mockPool (mockID, name, draw) VALUES (?, ?, ?)`, mockID, pool.Name, pool.Draw); err != nil {
+			return data.SQLiteErrorComparator(err)
+		}
+	}
+	return nil
+}
+
+func getMockPools(ctx context.Context, db querier, mockID string) ([]entities.MockPool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT mockID, name, draw FROM mockPool WHERE mockID = ? ORDER BY name`, mockID)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	var pools []entities.MockPool
+	for rows.Next() {
+		var pool entities.MockPool
+		if err := rows.Scan(&pool.MockID, &pool.Name, &pool.Draw); err != nil {
+			return nil, err
+		}
+		pools = append(pools, pool)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
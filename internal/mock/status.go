@@ -0,0 +1,66 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+)
+
+var ErrMockNotPublished = errs.NewError(errors.New("mock is not open to candidates"), errs.DataErrorType, errs.ErrConflict)
+
+// [K : current status] [V : statuses it may move to]
+// Archiving goes through DeleteMock, which also stamps ArchivedAt.
+var statusTransitions = map[string][]string{
+	entities.MockDraft:     {entities.MockPublished},
+	entities.MockPublished: {entities.MockClosed},
+	entities.MockClosed:    {entities.MockPublished},
+	entities.MockArchived:  {},
+}
+
+// Whether a mock may move from one status to the other.
+func CanTransition(from string, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// Move a mock to a new status. Only its author or an admin may do so.
+func SetStatus(ctx context.Context, db *sql.DB, id string, user *entities.User, status string) (*entities.Mock, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	m, err := getMockMeta(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CanDelete(m, user) {
+		return nil, errs.NewError(errors.New("only the author or an admin may change the status of this mock"), errs.DataErrorType, errs.ErrForbidden)
+	}
+
+	current := m.Status
+	if !CanTransition(current, status) {
+		return nil, errs.NewError(fmt.Errorf("a %s mock cannot be %s", current, status), errs.DataErrorType, errs.ErrConflict)
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `UPDATE mock SET status = ?, lastUpdatedAt = ? WHERE id = ?`, status, now, id); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	m.Status = status
+	m.LastUpdatedAt = now
+	return m, nil
+}
@@ -0,0 +1,69 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/ashtonx86/mocker/internal/data"
+)
+
+// Lowercase, trim and deduplicate tags, keeping their first-seen order.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+func replaceMockTags(ctx context.Context, tx *sql.Tx, mockID string, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mockTag WHERE mockID = ?`, mockID); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+
+	for _, tag := range normalizeTags(tags) {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO mockTag (mockID, tag) VALUES (?, ?)`, mockID, tag); err != nil {
+			return data.SQLiteErrorComparator(err)
+		}
+	}
+	return nil
+}
+
+// Tags of several mocks at once, [K : mockID] [V : tags].
+func getMockTags(ctx context.Context, db querier, mockIDs []string) (map[string][]string, error) {
+	tags := make(map[string][]string, len(mockIDs))
+	if len(mockIDs) == 0 {
+		return tags, nil
+	}
+
+	placeholders := make([]string, len(mockIDs))
+	args := make([]any, len(mockIDs))
+	for i, id := range mockIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	stmt := `SELECT mockID, tag FROM mockTag WHERE mockID IN (` + strings.Join(placeholders, ", ") + `) ORDER BY rowid`
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mockID, tag string
+		if err := rows.Scan(&mockID, &tag); err != nil {
+			return nil, err
+		}
+		tags[mockID] = append(tags[mockID], tag)
+	}
+	return tags, rows.Err()
+}
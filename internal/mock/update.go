@@ -0,0 +1,423 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/schemas"
+)
+
+var (
+	ErrStructuralChange = errs.NewError(errors.New("structural changes are not allowed while sessions for this mock are active"), errs.DataErrorType, errs.ErrConflict)
+	ErrNotMockAuthor    = errs.NewError(errors.New("only the author may update this mock"), errs.DataErrorType, errs.ErrForbidden)
+)
+
+type UpdateOptions struct {
+	// Structural changes alter how sessions are graded: adding or removing
+	// questions and options, changing points, correct answers or the
+	// scoring policy. They are refused unless this is set.
+	AllowStructural bool
+
+	// For bank questions, the mocks linking the question that AllowStructural was decided for.
+	// A structural change is still refused if the question is linked by any other mock.
+	LinkedMocks []string
+}
+
+// Apply an update to a mock, diffing its questions and options in one transaction.
+// Only the author of the mock may update it.
+func UpdateMock(ctx context.Context, db *sql.DB, id string, editorID string, req schemas.MockUpdateRequest, opts UpdateOptions) (*FullMock, error) {
+	if err := ValidateMockUpdate(req); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errs.NewError(err, errs.SQLErrorType, errs.ErrInternalFailure)
+	}
+	defer tx.Rollback()
+
+	current, err := getMock(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.AuthorID != editorID {
+		return nil, ErrNotMockAuthor
+	}
+
+	now := time.Now()
+	structural, err := updateMockRow(ctx, tx, current, req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Tags != nil {
+		if err := replaceMockTags(ctx, tx, id, *req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Questions != nil {
+		changed, err := diffQuestions(ctx, tx, current, *req.Questions, now)
+		if err != nil {
+			return nil, err
+		}
+		structural = structural || changed
+	}
+
+	if req.BankQuestions != nil {
+		changed, err := replaceBankLinks(ctx, tx, id, current.AuthorID, *req.BankQuestions, now)
+		if err != nil {
+			return nil, err
+		}
+		structural = structural || changed
+	}
+
+	if req.Pools != nil {
+		if err := replacePools(ctx, tx, id, *req.Pools); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Questions != nil || req.BankQuestions != nil {
+		if err := checkHasQuestions(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Questions != nil || req.BankQuestions != nil || req.Pools != nil {
+		updated, err := getMock(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMockPools(updated); err != nil {
+			return nil, err
+		}
+	}
+
+	if structural && !opts.AllowStructural {
+		return nil, ErrStructuralChange
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+
+	return GetMock(ctx, db, id)
+}
+
+func updateMockRow(ctx context.Context, tx *sql.Tx, current *FullMock, req schemas.MockUpdateRequest, now time.Time) (bool, error) {
+	m := current.Mock
+	structural := false
+
+	if req.Topic != nil {
+		m.Topic = *req.Topic
+	}
+	if req.Instructions != nil {
+		m.Instructions = *req.Instructions
+	}
+	if req.TimeMins != nil {
+		m.TimeMins = *req.TimeMins
+	}
+	if req.ReviewPolicy != nil {
+		m.ReviewPolicy = *req.ReviewPolicy
+	}
+	if req.NegativeMarking != nil && *req.NegativeMarking != m.NegativeMarking {
+		m.NegativeMarking = *req.NegativeMarking
+		structural = true
+	}
+	if req.FloorAtZero != nil && *req.FloorAtZero != m.FloorAtZero {
+		m.FloorAtZero = *req.FloorAtZero
+		structural = true
+	}
+	if req.PartialCredit != nil && *req.PartialCredit != m.PartialCredit {
+		m.PartialCredit = *req.PartialCredit
+		structural = true
+	}
+
+	// Sessions keep the order they were given, only later sessions follow a change.
+	if req.ShuffleQuestions != nil {
+		m.ShuffleQuestions = *req.ShuffleQuestions
+	}
+	if req.ShuffleOptions != nil {
+		m.ShuffleOptions = *req.ShuffleOptions
+	}
+
+	if req.MaxAttempts != nil {
+		m.MaxAttempts = *req.MaxAttempts
+	}
+	if req.CooldownMins != nil {
+		m.CooldownMins = *req.CooldownMins
+	}
+
+	// Stored window times keep their instant when only the timezone changes.
+	if req.Timezone != nil {
+		m.Timezone = *req.Timezone
+	}
+	loc, err := windowLocation(m.Timezone)
+	if err != nil {
+		return false, err
+	}
+	if req.OpensAt != nil {
+		if m.OpensAt, err = parseWindowTime(*req.OpensAt, loc); err != nil {
+			return false, err
+		}
+	}
+	if req.ClosesAt != nil {
+		if m.ClosesAt, err = parseWindowTime(*req.ClosesAt, loc); err != nil {
+			return false, err
+		}
+	}
+	if err := checkWindow(m.OpensAt, m.ClosesAt); err != nil {
+		return false, err
+	}
+
+	if req.Visibility != nil {
+		m.Visibility = *req.Visibility
+	}
+	// The hash never leaves the database, so it is read back rather than taken from current.
+	var accessCodeHash string
+	if req.AccessCode != nil {
+		if accessCodeHash, err = hashAccessCode(*req.AccessCode); err != nil {
+			return false, err
+		}
+	} else if err := tx.QueryRowContext(ctx, `SELECT accessCodeHash FROM mock WHERE id = ?`, m.ID).Scan(&accessCodeHash); err != nil {
+		return false, data.SQLiteErrorComparator(err)
+	}
+	if m.Visibility == entities.VisibilityCode && accessCodeHash == "" {
+		return false, errs.NewError(errors.New("a mock with code visibility needs an access code"), errs.DataErrorType, errs.ErrDataIllegal)
+	}
+
+	stmt := `
+        UPDATE mock
+        SET topic = ?, instructions = ?, timeMins = ?, reviewPolicy = ?, negativeMarking = ?, floorAtZero = ?, partialCredit = ?, visibility = ?, accessCodeHash = ?, shuffleQuestions = ?, shuffleOptions = ?, maxAttempts = ?, cooldownMins = ?, opensAt = ?, closesAt = ?, timezone = ?, lastUpdatedAt = ?
+        WHERE id = ?
+    `
+	vals := []any{m.Topic, m.Instructions, m.TimeMins, m.ReviewPolicy, m.NegativeMarking, m.FloorAtZero, m.PartialCredit, m.Visibility, accessCodeHash, m.ShuffleQuestions, m.ShuffleOptions, m.MaxAttempts, m.CooldownMins, m.OpensAt, m.ClosesAt, m.Timezone, now, m.ID}
+
+	if _, err := tx.ExecContext(ctx, stmt, vals...); err != nil {
+		return false, data.SQLiteErrorComparator(err)
+	}
+	return structural, nil
+}
+
+// Reconcile the stored questions with the desired list, reporting whether the change was structural.
+func diffQuestions(ctx context.Context, tx *sql.Tx, current *FullMock, desired []schemas.MockQuestionUpdateSchema, now time.Time) (bool, error) {
+	// Bank questions are edited in the bank and linked through BankQuestions.
+	existing := make(map[string]FullMockQuestion, len(current.Questions))
+	for _, q := range current.Questions {
+		if !q.FromBank {
+			existing[q.ID] = q
+		}
+	}
+
+	structural := false
+	kept := make(map[string]bool, len(desired))
+
+	updateStmt := `
+        UPDATE mockQuestion
+        SET type = ?, problem = ?, points = ?, correctOptionID = ?, explanation = ?, negativePoints = ?, numericAnswer = ?, tolerance = ?, position = ?, pool = ?, lastUpdatedAt = ?
+        WHERE id = ?
+    `
+
+	for position, q := range desired {
+		if q.ID == "" {
+			if _, err := insertMockQuestion(ctx, tx, current.ID, position, newQuestionSchema(q)); err != nil {
+				return false, err
+			}
+			structural = true
+			continue
+		}
+
+		cur, ok := existing[q.ID]
+		if !ok || kept[q.ID] {
+			return false, errs.NewError(fmt.Errorf("question %q does not belong to this mock or is listed twice", q.ID), errs.DataErrorType, errs.ErrDataIllegal)
+		}
+		kept[q.ID] = true
+
+		typ := q.Type
+		if typ == "" {
+			typ = entities.QuestionSingleChoice
+		}
+
+		correctOptionID, optStructural, err := diffQuestionOptions(ctx, tx, mockOptionTable, cur, typ, q, now)
+		if err != nil {
+			return false, err
+		}
+		structural = structural || optStructural
+
+		grading := cur.QuestionType() != typ || cur.Points != q.Points || cur.CorrectOptionID != correctOptionID ||
+			!equalFloatPtr(cur.NegativePoints, q.NegativePoints) || !equalFloatPtr(cur.NumericAnswer, q.NumericAnswer) || cur.Tolerance != q.Tolerance
+		structural = structural || grading
+
+		// Sessions keep the questions they drew, moving one between pools only affects later draws.
+		wording := cur.Problem != q.Problem || cur.Explanation != q.Explanation || cur.Position != position || cur.Pool != q.Pool
+		if grading || wording {
+			vals := []any{typ, q.Problem, q.Points, correctOptionID, q.Explanation, q.NegativePoints, q.NumericAnswer, q.Tolerance, position, q.Pool, now, q.ID}
+			if _, err := tx.ExecContext(ctx, updateStmt, vals...); err != nil {
+				return false, data.SQLiteErrorComparator(err)
+			}
+		}
+	}
+
+	for id := range existing {
+		if kept[id] {
+			continue
+		}
+		if err := deleteMockQuestion(ctx, tx, id); err != nil {
+			return false, err
+		}
+		structural = true
+	}
+
+	return structural, nil
+}
+
+// Reconcile the options of a question and resolve its correct option, returning its ID
+// for single-answer questions. Options are written first, the correct option may be a new one.
+func diffQuestionOptions(ctx context.Context, tx *sql.Tx, table string, current FullMockQuestion, typ string, q schemas.MockQuestionUpdateSchema, now time.Time) (string, bool, error) {
+	options := slices.Clone(q.Options)
+	correct := 0
+	if isSingleAnswer(typ) {
+		numbers := make([]int, len(options))
+		flags := make([]bool, len(options))
+		for i, opt := range options {
+			numbers[i], flags[i] = opt.Number, opt.IsCorrect
+		}
+
+		n, err := correctNumber(q.CorrectOptionNumber, numbers, flags)
+		if err != nil {
+			return "", false, err
+		}
+		for i := range options {
+			options[i].IsCorrect = options[i].Number == n
+		}
+		correct = n
+	}
+
+	finalOptions, structural, err := diffOptions(ctx, tx, table, current, typ, options, now)
+	if err != nil {
+		return "", false, err
+	}
+
+	if !isSingleAnswer(typ) {
+		return "", structural, nil
+	}
+	return optionWithNumber(finalOptions, correct), structural, nil
+}
+
+// Reconcile the options of one question. Rewording an option is not structural, and neither is
+// renumbering it unless the question is graded on option order.
+// Returns the options as they stand afterwards.
+func diffOptions(ctx context.Context, tx *sql.Tx, table string, current FullMockQuestion, typ string, desired []schemas.MockOptionUpdateSchema, now time.Time) ([]entities.MockOption, bool, error) {
+	existing := make(map[string]entities.MockOption, len(current.Options))
+	for _, opt := range current.Options {
+		existing[opt.ID] = opt
+	}
+
+	structural := false
+	kept := make(map[string]bool, len(desired))
+	final := make([]entities.MockOption, 0, len(desired))
+
+	updateStmt := fmt.Sprintf(`UPDATE %s SET number = ?, option = ?, isCorrect = ?, match = ?, matchID = ?, lastUpdatedAt = ? WHERE id = ?`, table)
+
+	for _, opt := range desired {
+		if opt.ID == "" {
+			option := newMockOption(current.ID, newOptionSchema(opt))
+			if err := insertOption(ctx, tx, table, option); err != nil {
+				return nil, false, err
+			}
+			final = append(final, option)
+			structural = true
+			continue
+		}
+
+		cur, ok := existing[opt.ID]
+		if !ok || kept[opt.ID] {
+			return nil, false, errs.NewError(fmt.Errorf("option %q does not belong to question %q or is listed twice", opt.ID, current.ID), errs.DataErrorType, errs.ErrDataIllegal)
+		}
+		kept[opt.ID] = true
+
+		grading := cur.IsCorrect != opt.IsCorrect || cur.Match != opt.Match || (typ == entities.QuestionOrdering && cur.Number != opt.Number)
+		structural = structural || grading
+
+		// A changed pairing gets a new match ID, so answers given against the old one no longer count.
+		matchID := cur.MatchID
+		if cur.Match != opt.Match {
+			matchID = newMatchID(opt.Match)
+		}
+
+		if grading || cur.Number != opt.Number || cur.Option != opt.Option {
+			vals := []any{opt.Number, opt.Option, opt.IsCorrect, opt.Match, matchID, now, opt.ID}
+			if _, err := tx.ExecContext(ctx, updateStmt, vals...); err != nil {
+				return nil, false, data.SQLiteErrorComparator(err)
+			}
+		}
+
+		cur.Number, cur.Option, cur.IsCorrect, cur.Match, cur.MatchID = opt.Number, opt.Option, opt.IsCorrect, opt.Match, matchID
+		final = append(final, cur)
+	}
+
+	for id := range existing {
+		if kept[id] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, table), id); err != nil {
+			return nil, false, data.SQLiteErrorComparator(err)
+		}
+		structural = true
+	}
+
+	return final, structural, nil
+}
+
+// Its options go with it.
+func deleteMockQuestion(ctx context.Context, tx *sql.Tx, questionID string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mockQuestion WHERE id = ?`, questionID); err != nil {
+		return data.SQLiteErrorComparator(err)
+	}
+	return nil
+}
+
+func newQuestionSchema(q schemas.MockQuestionUpdateSchema) schemas.MockQuestionSchema {
+	options := make([]schemas.MockOptionSchema, 0, len(q.Options))
+	for _, opt := range q.Options {
+		options = append(options, newOptionSchema(opt))
+	}
+
+	return schemas.MockQuestionSchema{
+		Type:                q.Type,
+		Problem:             q.Problem,
+		Points:              q.Points,
+		CorrectOptionNumber: q.CorrectOptionNumber,
+		Explanation:         q.Explanation,
+		NegativePoints:      q.NegativePoints,
+		NumericAnswer:       q.NumericAnswer,
+		Tolerance:           q.Tolerance,
+		Pool:                q.Pool,
+		Options:             options,
+	}
+}
+
+func newOptionSchema(opt schemas.MockOptionUpdateSchema) schemas.MockOptionSchema {
+	return schemas.MockOptionSchema{
+		Number:    opt.Number,
+		Option:    opt.Option,
+		IsCorrect: opt.IsCorrect,
+		Match:     opt.Match,
+	}
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
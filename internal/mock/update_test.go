@@ -0,0 +1,287 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+// The questions of a mock as an update that changes nothing.
+func editable(m *mock.FullMock) []schemas.MockQuestionUpdateSchema {
+	questions := make([]schemas.MockQuestionUpdateSchema, 0, len(m.Questions))
+	for _, q := range m.Questions {
+		u := schemas.MockQuestionUpdateSchema{
+			ID: q.ID, Type: q.QuestionType(), Problem: q.Problem, Points: q.Points, Explanation: q.Explanation,
+			NegativePoints: q.NegativePoints, NumericAnswer: q.NumericAnswer, Tolerance: q.Tolerance, Pool: q.Pool,
+		}
+		for _, opt := range q.Options {
+			u.Options = append(u.Options, schemas.MockOptionUpdateSchema{ID: opt.ID, Number: opt.Number, Option: opt.Option, IsCorrect: opt.IsCorrect, Match: opt.Match})
+		}
+		questions = append(questions, u)
+	}
+	return questions
+}
+
+func TestUpdateMockQuestions(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author")
+
+	create := func() *mock.FullMock {
+		created, err := mock.CreateMock(ctx, db, schemas.MockCreateRequest{
+			Topic: "Diff", Instructions: "i", TimeMins: 30, AuthorID: "author",
+			Questions: []schemas.MockQuestionSchema{
+				{Problem: "Pick", Points: 2, CorrectOptionNumber: 2, Explanation: "b it is", Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}, {Number: 3, Option: "c"}}},
+				{Type: entities.QuestionOrdering, Problem: "Sort", Points: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "first"}, {Number: 2, Option: "second"}, {Number: 3, Option: "third"}}},
+				{Type: entities.QuestionMatching, Problem: "Match", Points: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "Na", Match: "Sodium"}, {Number: 2, Option: "Fe", Match: "Iron"}}},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := mock.GetMock(ctx, db, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name       string
+		edit       func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema
+		structural bool
+		check      func(t *testing.T, before *mock.FullMock, after *mock.FullMock)
+	}{
+		{
+			name: "reword a question and its explanation",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Problem, qs[0].Explanation = "Pick one", "b, obviously"
+				return qs
+			},
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if q := after.Questions[0]; q.Problem != "Pick one" || q.Explanation != "b, obviously" || q.CorrectOptionID != before.Questions[0].CorrectOptionID {
+					t.Errorf("expected the wording to change and nothing else, got %+v", q.MockQuestion)
+				}
+			},
+		},
+		{
+			name: "reword an option",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Options[0].Option = "alpha"
+				return qs
+			},
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if opt := after.Questions[0].Options[0]; opt.ID != before.Questions[0].Options[0].ID || opt.Option != "alpha" {
+					t.Errorf("expected the option to keep its ID under its new wording, got %+v", opt)
+				}
+			},
+		},
+		{
+			name: "renumber the options of a single choice question",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Options[0].Number, qs[0].Options[2].Number = 3, 1
+				return qs
+			},
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				q := after.Questions[0]
+				if q.Options[0].Option != "c" || q.CorrectOptionID != before.Questions[0].CorrectOptionID {
+					t.Errorf("expected c first and the correct option unchanged, got %+v", q)
+				}
+			},
+		},
+		{
+			name: "reorder the questions",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				return []schemas.MockQuestionUpdateSchema{qs[2], qs[0], qs[1]}
+			},
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if after.Questions[0].ID != before.Questions[2].ID || after.Questions[1].ID != before.Questions[0].ID {
+					t.Errorf("expected the matching question first, got %+v", after.Questions)
+				}
+			},
+		},
+		{
+			name: "reword the left side of a pair",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[2].Options[0].Option = "Natrium"
+				return qs
+			},
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if opt := after.Questions[2].Options[0]; opt.MatchID != before.Questions[2].Options[0].MatchID {
+					t.Errorf("expected the pairing to keep its match ID, got %+v", opt)
+				}
+			},
+		},
+		{
+			name: "change the correct option",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Options[1].IsCorrect, qs[0].Options[2].IsCorrect = false, true
+				return qs
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if q := after.Questions[0]; q.CorrectOptionID != before.Questions[0].Options[2].ID || q.Options[1].IsCorrect || !q.Options[2].IsCorrect {
+					t.Errorf("expected c to be correct, got %+v", q)
+				}
+			},
+		},
+		{
+			name: "change the correct option by number",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Options[1].IsCorrect = false
+				qs[0].CorrectOptionNumber = 1
+				return qs
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if q := after.Questions[0]; q.CorrectOptionID != before.Questions[0].Options[0].ID {
+					t.Errorf("expected a to be correct, got %+v", q)
+				}
+			},
+		},
+		{
+			name: "add an option and make it the correct one",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Options[1].IsCorrect = false
+				qs[0].Options = append(qs[0].Options, schemas.MockOptionUpdateSchema{Number: 4, Option: "d", IsCorrect: true})
+				return qs
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				q := after.Questions[0]
+				if len(q.Options) != 4 || q.Options[3].Option != "d" || q.CorrectOptionID != q.Options[3].ID {
+					t.Errorf("expected the new option d to be correct, got %+v", q)
+				}
+			},
+		},
+		{
+			name: "remove an option",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Options = qs[0].Options[:2]
+				return qs
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if n := len(after.Questions[0].Options); n != 2 {
+					t.Errorf("expected 2 options left, got %d", n)
+				}
+			},
+		},
+		{
+			name: "change the points",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[0].Points = 5
+				return qs
+			},
+			structural: true,
+		},
+		{
+			name: "set negative points",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				penalty := 0.5
+				qs[0].NegativePoints = &penalty
+				return qs
+			},
+			structural: true,
+		},
+		{
+			name: "renumber the options of an ordering question",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[1].Options[0].Number, qs[1].Options[2].Number = 3, 1
+				return qs
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if opt := after.Questions[1].Options[0]; opt.Option != "third" {
+					t.Errorf("expected third to come first, got %+v", after.Questions[1].Options)
+				}
+			},
+		},
+		{
+			name: "change a pairing",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				qs[2].Options[1].Match = "Ferrum"
+				return qs
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if opt := after.Questions[2].Options[1]; opt.Match != "Ferrum" || opt.MatchID == before.Questions[2].Options[1].MatchID {
+					t.Errorf("expected the new pairing under a new match ID, got %+v", opt)
+				}
+			},
+		},
+		{
+			name: "add a question",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				return append(qs, schemas.MockQuestionUpdateSchema{Problem: "New", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionUpdateSchema{{Number: 1, Option: "x"}, {Number: 2, Option: "y"}}})
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if len(after.Questions) != 4 || after.Questions[3].Problem != "New" || after.Questions[3].CorrectOptionID == "" {
+					t.Errorf("expected the new question last with its correct option, got %+v", after.Questions)
+				}
+			},
+		},
+		{
+			name: "remove a question",
+			edit: func(qs []schemas.MockQuestionUpdateSchema) []schemas.MockQuestionUpdateSchema {
+				return qs[1:]
+			},
+			structural: true,
+			check: func(t *testing.T, before *mock.FullMock, after *mock.FullMock) {
+				if len(after.Questions) != 2 || after.Questions[0].ID != before.Questions[1].ID {
+					t.Errorf("expected the first question to be gone, got %+v", after.Questions)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := create()
+			questions := tt.edit(editable(before))
+			req := schemas.MockUpdateRequest{Questions: &questions}
+
+			_, err := mock.UpdateMock(ctx, db, before.ID, "author", req, mock.UpdateOptions{})
+			if tt.structural != errors.Is(err, mock.ErrStructuralChange) {
+				t.Fatalf("expected structural=%v, got %v", tt.structural, err)
+			}
+			if tt.structural {
+				unchanged, err := mock.GetMock(ctx, db, before.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !unchanged.LastUpdatedAt.Equal(before.LastUpdatedAt) {
+					t.Fatal("expected a refused update to change nothing")
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			after, err := mock.UpdateMock(ctx, db, before.ID, "author", req, mock.UpdateOptions{AllowStructural: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.check != nil {
+				tt.check(t, before, after)
+			}
+		})
+	}
+
+	t.Run("refuse options of another question", func(t *testing.T) {
+		before := create()
+		questions := editable(before)
+		questions[0].Options[0].ID = before.Questions[1].Options[0].ID
+
+		_, err := mock.UpdateMock(ctx, db, before.ID, "author", schemas.MockUpdateRequest{Questions: &questions}, mock.UpdateOptions{AllowStructural: true})
+		if !errors.Is(err, errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}) {
+			t.Fatalf("expected the foreign option to be refused, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/schemas"
+)
+
+// A mock longer than a day is a typo, not an exam.
+const MaxTimeMins = 24 * 60
+
+/*
+* errs.Validate checks fields one at a time. ValidateMock checks the rules
+* that span fields: unique option numbers and text within a question, unique
+* problems within a mock, a positive point total, a sane duration, an
+* availability window that closes after it opens, bank questions linked once
+* and pools that are declared and hold enough questions to draw from.
+* Every problem is reported, each with the path of the offending value,
+* e.g. "questions[3].options[1].number".
+ */
+func ValidateMock(req schemas.MockCreateRequest) error {
+	var p problems
+	p.checkTimeMins(req.TimeMins)
+	p.checkWindow(req.OpensAt, req.ClosesAt, req.Timezone)
+	if req.Visibility == entities.VisibilityCode && req.AccessCode == "" {
+		p.add("access_code", "required_if=visibility code", "")
+	}
+	if len(req.Questions) == 0 && len(req.BankQuestions) == 0 {
+		p.add("questions", "required_without=bank_questions", nil)
+	}
+	p.checkQuestions(req.Questions)
+	p.checkBankQuestions(req.BankQuestions)
+
+	p.checkPoolNames(req.Pools)
+	refs := make([]poolRef, 0, len(req.Questions)+len(req.BankQuestions))
+	for i, q := range req.Questions {
+		refs = append(refs, poolRef{path: fmt.Sprintf("questions[%d].pool", i), pool: q.Pool})
+	}
+	for i, link := range req.BankQuestions {
+		refs = append(refs, poolRef{path: fmt.Sprintf("bank_questions[%d].pool", i), pool: link.Pool})
+	}
+	p.checkPoolRefs(req.Pools, refs)
+	return p.err()
+}
+
+// Same as ValidateMock, for the fields an update sets.
+func ValidateMockUpdate(req schemas.MockUpdateRequest) error {
+	var p problems
+	if req.TimeMins != nil {
+		p.checkTimeMins(*req.TimeMins)
+	}
+	// Sides left out are merged with the stored window by UpdateMock, which checks the result.
+	p.checkWindow(deref(req.OpensAt), deref(req.ClosesAt), deref(req.Timezone))
+	if req.Questions != nil {
+		questions := make([]schemas.MockQuestionSchema, len(*req.Questions))
+		for i, q := range *req.Questions {
+			questions[i] = newQuestionSchema(q)
+		}
+		p.checkQuestions(questions)
+	}
+	if req.BankQuestions != nil {
+		p.checkBankQuestions(*req.BankQuestions)
+	}
+	// Pool references are checked by UpdateMock against the merged mock.
+	if req.Pools != nil {
+		p.checkPoolNames(*req.Pools)
+	}
+	return p.err()
+}
+
+// Checks the content of a bank question, which has no mock around it.
+func ValidateBankQuestion(q schemas.MockQuestionSchema) error {
+	var p problems
+	p.checkOptions("", q)
+	return p.err()
+}
+
+type problems []errs.ValidationErrorResponse
+
+func (p *problems) add(path string, tag string, value any) {
+	*p = append(*p, errs.ValidationErrorResponse{FailedField: path, Tag: tag, Value: value})
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	joined := make([]error, len(p))
+	for i, ve := range p {
+		joined[i] = ve
+	}
+	return errs.NewError(errors.Join(joined...), errs.DataErrorType, errs.ErrDataIllegal)
+}
+
+func (p *problems) checkTimeMins(timeMins int) {
+	if timeMins > MaxTimeMins {
+		p.add("time_mins", "max", timeMins)
+	}
+}
+
+func (p *problems) checkWindow(opensAt string, closesAt string, timezone string) {
+	loc, err := windowLocation(timezone)
+	if err != nil {
+		p.add("timezone", "timezone", timezone)
+		loc = time.UTC
+	}
+
+	opens, err := parseWindowTime(opensAt, loc)
+	if err != nil {
+		p.add("opens_at", "datetime", opensAt)
+	}
+	closes, err := parseWindowTime(closesAt, loc)
+	if err != nil {
+		p.add("closes_at", "datetime", closesAt)
+	}
+
+	if checkWindow(opens, closes) != nil {
+		p.add("closes_at", "gtfield=opens_at", closesAt)
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (p *problems) checkQuestions(questions []schemas.MockQuestionSchema) {
+	total := 0
+	problemAt := make(map[string]int, len(questions))
+
+	for i, q := range questions {
+		path := fmt.Sprintf("questions[%d]", i)
+		total += q.Points
+
+		key := normalizeText(q.Problem)
+		if first, ok := problemAt[key]; ok {
+			p.add(path+".problem", fmt.Sprintf("unique=questions[%d]", first), q.Problem)
+		} else {
+			problemAt[key] = i
+		}
+
+		p.checkOptions(path, q)
+	}
+
+	if len(questions) > 0 && total <= 0 {
+		p.add("questions", "points_total", total)
+	}
+}
+
+func (p *problems) checkOptions(path string, q schemas.MockQuestionSchema) {
+	numberAt := make(map[int]int, len(q.Options))
+	textAt := make(map[string]int, len(q.Options))
+	matchAt := make(map[string]int, len(q.Options))
+
+	for j, opt := range q.Options {
+		optPath := fmt.Sprintf("options[%d]", j)
+		if path != "" {
+			optPath = path + "." + optPath
+		}
+
+		if first, ok := numberAt[opt.Number]; ok {
+			p.add(optPath+".number", fmt.Sprintf("unique=options[%d]", first), opt.Number)
+		} else {
+			numberAt[opt.Number] = j
+		}
+
+		key := normalizeText(opt.Option)
+		if first, ok := textAt[key]; ok {
+			p.add(optPath+".option", fmt.Sprintf("unique=options[%d]", first), opt.Option)
+		} else {
+			textAt[key] = j
+		}
+
+		// Two identical right-hand sides make a matching question ambiguous.
+		if q.Type == entities.QuestionMatching && opt.Match != "" {
+			key := normalizeText(opt.Match)
+			if first, ok := matchAt[key]; ok {
+				p.add(optPath+".match", fmt.Sprintf("unique=options[%d]", first), opt.Match)
+			} else {
+				matchAt[key] = j
+			}
+		}
+	}
+}
+
+func (p *problems) checkBankQuestions(links []schemas.MockBankQuestionSchema) {
+	linkAt := make(map[string]int, len(links))
+	for i, link := range links {
+		if first, ok := linkAt[link.QuestionID]; ok {
+			p.add(fmt.Sprintf("bank_questions[%d].question_id", i), fmt.Sprintf("unique=bank_questions[%d]", first), link.QuestionID)
+		} else {
+			linkAt[link.QuestionID] = i
+		}
+	}
+}
+
+// Text that only differs in case or surrounding whitespace reads the same to a candidate.
+func normalizeText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,55 @@
+package mock_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+)
+
+func TestValidateMock(t *testing.T) {
+	valid := schemas.MockCreateRequest{
+		Topic:        "Chemistry",
+		Instructions: "Answer everything",
+		TimeMins:     30,
+		Questions: []schemas.MockQuestionSchema{
+			{Problem: "Symbol of sodium?", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "Na"}, {Number: 2, Option: "So"}}},
+			{Problem: "Symbol of iron?", Points: 1, CorrectOptionNumber: 2, Options: []schemas.MockOptionSchema{{Number: 1, Option: "Ir"}, {Number: 2, Option: "Fe"}}},
+		},
+	}
+	if err := mock.ValidateMock(valid); err != nil {
+		t.Fatalf("expected a valid mock, got %v", err)
+	}
+
+	broken := valid
+	broken.TimeMins = 100000
+	broken.Questions = []schemas.MockQuestionSchema{
+		valid.Questions[0],
+		{Problem: " symbol of SODIUM? ", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "Na"}, {Number: 1, Option: "na"}}},
+		{Type: "matching", Problem: "Match the symbols", Points: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "Na", Match: "Sodium"}, {Number: 2, Option: "K", Match: "sodium"}}},
+	}
+
+	err := mock.ValidateMock(broken)
+	if !errors.Is(err, errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}) {
+		t.Fatalf("expected ErrDataIllegal, got %v", err)
+	}
+
+	for _, path := range []string{
+		"time_mins",
+		"questions[1].problem",
+		"questions[1].options[1].number",
+		"questions[1].options[1].option",
+		"questions[2].options[1].match",
+	} {
+		if !strings.Contains(err.Error(), "[FailedField : "+path+"]") {
+			t.Errorf("expected a problem at %s, got %v", path, err)
+		}
+	}
+
+	if n := strings.Count(err.Error(), "validation error"); n != 5 {
+		t.Errorf("expected 5 problems, got %d :: %v", n, err)
+	}
+}
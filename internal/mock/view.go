@@ -0,0 +1,204 @@
+package mock
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+)
+
+// What a user has done with a mock, which decides how much of it they may see.
+type Access struct {
+	InSession bool // The user has an active session for the mock.
+	Submitted bool // The user has submitted at least one attempt at the mock.
+
+	// IDs of the questions the user was given across their attempts, which
+	// only cover what they drew from the mock's pools.
+	Attempted []string
+
+	// The questions of the user's session in the order it shows them, nil outside a session.
+	Order []QuestionOrder
+}
+
+// A question of a session. Options and matches keep the mock's order when their IDs are nil.
+type QuestionOrder struct {
+	ID        string
+	OptionIDs []string
+	MatchIDs  []string
+}
+
+// A mock without its answer key: no correct options, numeric answers,
+// explanations or matching pairs.
+type CandidateMock struct {
+	entities.Mock
+	Tags          []string            `json:"tags"`
+	QuestionCount int                 `json:"question_count"`
+	Questions     []CandidateQuestion `json:"questions,omitempty"` // Left out until the user starts a session.
+}
+
+type CandidateQuestion struct {
+	ID             string            `json:"id"`
+	Type           string            `json:"type"`
+	Problem        string            `json:"problem"`
+	Points         int               `json:"points"`
+	NegativePoints *float64          `json:"negative_points,omitempty"`
+	Position       int               `json:"position"`
+	Options        []CandidateOption `json:"options"`           // In the mock's order, but never in the solved order of an ordering question.
+	Matches        []CandidateMatch  `json:"matches,omitempty"` // Right-hand sides of a matching question, sorted by text.
+}
+
+// Options go without their number, which is the answer to an ordering question.
+type CandidateOption struct {
+	ID     string `json:"id"`
+	Option string `json:"option"`
+}
+
+type CandidateMatch struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+/*
+* The part of the mock the user may see:
+*   the author or an admin             : everything
+*   in a session                       : the session's questions and options, no answers
+*   after submitting, if the review
+*   policy allows it                   : the questions attempted, answers included
+*   after submitting                   : the questions attempted and their options, no answers
+*   anyone else                        : the mock's details only
+ */
+func (m *FullMock) VisibleTo(user *entities.User, access Access) any {
+	if CanDelete(&m.Mock, user) {
+		return m
+	}
+	if access.InSession {
+		c := m.Redacted(true)
+		if access.Order != nil {
+			c.Arrange(access.Order)
+		}
+		return c
+	}
+	if access.Submitted {
+		attempted := m.only(access.Attempted)
+		if m.ReviewPolicy == entities.ReviewAfterSubmit {
+			return attempted
+		}
+		return attempted.Redacted(true)
+	}
+	return m.Redacted(false)
+}
+
+// A copy of the mock with only the given questions, in the mock's order.
+func (m *FullMock) only(questionIDs []string) *FullMock {
+	kept := *m
+	kept.Questions = make([]FullMockQuestion, 0, len(questionIDs))
+	for _, q := range m.Questions {
+		if slices.Contains(questionIDs, q.ID) {
+			kept.Questions = append(kept.Questions, q)
+		}
+	}
+	return &kept
+}
+
+// Put the questions in the order of a session, leaving out the ones it did not draw.
+// Positions are renumbered, so sorting by them keeps the session's order.
+func (c *CandidateMock) Arrange(order []QuestionOrder) {
+	byID := make(map[string]CandidateQuestion, len(c.Questions))
+	for _, q := range c.Questions {
+		byID[q.ID] = q
+	}
+
+	arranged := make([]CandidateQuestion, 0, len(order))
+	for _, o := range order {
+		q, ok := byID[o.ID]
+		if !ok {
+			continue
+		}
+		q.Position = len(arranged)
+		if o.OptionIDs != nil {
+			q.Options = arrangeBy(q.Options, o.OptionIDs, func(opt CandidateOption) string { return opt.ID })
+		}
+		if o.MatchIDs != nil {
+			q.Matches = arrangeBy(q.Matches, o.MatchIDs, func(m CandidateMatch) string { return m.ID })
+		}
+		arranged = append(arranged, q)
+	}
+
+	c.Questions = arranged
+	c.QuestionCount = len(arranged)
+}
+
+// Items in the order of ids, followed by any the ids do not name in their current order.
+func arrangeBy[T any](items []T, ids []string, id func(T) string) []T {
+	rank := make(map[string]int, len(ids))
+	for i, v := range ids {
+		rank[v] = i
+	}
+
+	arranged := make([]T, len(items))
+	copy(arranged, items)
+	sort.SliceStable(arranged, func(i, j int) bool {
+		ri, ok := rank[id(arranged[i])]
+		if !ok {
+			ri = len(ids)
+		}
+		rj, ok := rank[id(arranged[j])]
+		if !ok {
+			rj = len(ids)
+		}
+		return ri < rj
+	})
+	return arranged
+}
+
+// Strip the answer key, keeping the questions only when asked to.
+func (m *FullMock) Redacted(withQuestions bool) *CandidateMock {
+	c := &CandidateMock{
+		Mock:          m.Mock,
+		Tags:          m.Tags,
+		QuestionCount: len(m.Questions),
+	}
+	if !withQuestions {
+		return c
+	}
+
+	c.Questions = make([]CandidateQuestion, 0, len(m.Questions))
+	for _, q := range m.Questions {
+		cq := CandidateQuestion{
+			ID:             q.ID,
+			Type:           q.QuestionType(),
+			Problem:        q.Problem,
+			Points:         q.Points,
+			NegativePoints: q.NegativePoints,
+			Position:       q.Position,
+			Options:        make([]CandidateOption, 0, len(q.Options)),
+		}
+
+		for _, opt := range q.Options {
+			cq.Options = append(cq.Options, CandidateOption{ID: opt.ID, Option: opt.Option})
+			if opt.Match != "" {
+				cq.Matches = append(cq.Matches, CandidateMatch{ID: opt.MatchID, Text: opt.Match})
+			}
+		}
+		// Listed in option order, matches would line up with their options.
+		sort.SliceStable(cq.Matches, func(i, j int) bool { return cq.Matches[i].Text < cq.Matches[j].Text })
+		// Listed in option order, an ordering question would come solved.
+		if cq.Type == entities.QuestionOrdering {
+			cq.Options = scrambled(cq.Options)
+		}
+
+		c.Questions = append(c.Questions, cq)
+	}
+	return c
+}
+
+// Options sorted by ID, which is random, and rotated when that happens to be their order.
+// Rotating takes every option off its place.
+func scrambled(options []CandidateOption) []CandidateOption {
+	sorted := slices.Clone(options)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	if len(sorted) > 1 && slices.Equal(sorted, options) {
+		sorted = append(sorted[1:], sorted[0])
+	}
+	return sorted
+}
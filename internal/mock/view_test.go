@@ -0,0 +1,116 @@
+package mock_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/mock"
+)
+
+func TestVisibleTo(t *testing.T) {
+	answer := 42.0
+	m := &mock.FullMock{
+		Mock: entities.Mock{ID: "m", AuthorID: "author", ReviewPolicy: entities.ReviewNever},
+		Questions: []mock.FullMockQuestion{
+			{
+				MockQuestion: entities.MockQuestion{ID: "q1", Problem: "Pick one", Points: 1, CorrectOptionID: "o1", Explanation: "because"},
+				Options:      []entities.MockOption{{ID: "o1", Number: 1, Option: "yes", IsCorrect: true}, {ID: "o2", Number: 2, Option: "no"}},
+			},
+			{
+				MockQuestion: entities.MockQuestion{ID: "q2", Type: entities.QuestionNumeric, Problem: "Answer?", Points: 1, NumericAnswer: &answer},
+			},
+			{
+				MockQuestion: entities.MockQuestion{ID: "q3", Type: entities.QuestionMatching, Problem: "Match", Points: 1},
+				Options: []entities.MockOption{
+					{ID: "o3", Number: 1, Option: "Na", Match: "Sodium", MatchID: "m3"},
+					{ID: "o4", Number: 2, Option: "Fe", Match: "Iron", MatchID: "m4"},
+				},
+			},
+			{
+				// Sorted by ID, the options would come in their solved order.
+				MockQuestion: entities.MockQuestion{ID: "q4", Type: entities.QuestionOrdering, Problem: "Sort", Points: 1},
+				Options:      []entities.MockOption{{ID: "o5", Number: 1, Option: "one"}, {ID: "o6", Number: 2, Option: "two"}, {ID: "o7", Number: 3, Option: "three"}},
+			},
+		},
+	}
+
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+	candidate := &entities.User{ID: "candidate", Role: entities.RoleUser}
+
+	encode := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	answerKey := []string{"correct_option_id", "is_correct", "explanation", "numeric_answer", `"match"`, "match_id", "number"}
+
+	if got := m.VisibleTo(author, mock.Access{}); got != m {
+		t.Fatalf("expected the author to see the full mock, got %T", got)
+	}
+
+	outsider := encode(m.VisibleTo(candidate, mock.Access{}))
+	if strings.Contains(outsider, "Pick one") {
+		t.Fatalf("expected no questions before a session starts, got %s", outsider)
+	}
+
+	inSession := encode(m.VisibleTo(candidate, mock.Access{InSession: true}))
+	if !strings.Contains(inSession, "Pick one") {
+		t.Fatalf("expected questions during a session, got %s", inSession)
+	}
+	for _, field := range answerKey {
+		if strings.Contains(inSession, field) {
+			t.Errorf("answer key leaked through %s :: %s", field, inSession)
+		}
+	}
+
+	redacted := m.Redacted(true)
+	if matches := redacted.Questions[2].Matches; len(matches) != 2 || matches[0].ID != "m4" || matches[1].ID != "m3" {
+		t.Errorf("expected matches sorted by text, got %+v", matches)
+	}
+	if options := redacted.Questions[3].Options; len(options) != 3 || options[0].ID == "o5" || options[1].ID == "o6" || options[2].ID == "o7" {
+		t.Errorf("expected every option of the ordering question off its place, got %+v", options)
+	}
+
+	order := []mock.QuestionOrder{{ID: "q3", OptionIDs: []string{"o4", "o3"}, MatchIDs: []string{"m3", "m4"}}, {ID: "q1"}}
+	arranged := m.VisibleTo(candidate, mock.Access{InSession: true, Order: order}).(*mock.CandidateMock)
+	if len(arranged.Questions) != 2 || arranged.QuestionCount != 2 || arranged.Questions[0].ID != "q3" || arranged.Questions[1].Position != 1 {
+		t.Fatalf("expected the session's questions in its order, got %+v", arranged.Questions)
+	}
+	if q := arranged.Questions[0]; q.Options[0].ID != "o4" || q.Matches[0].ID != "m3" {
+		t.Errorf("expected options and matches in the session's order, got %+v", q)
+	}
+	if q := arranged.Questions[1]; q.Options[0].ID != "o1" {
+		t.Errorf("expected options to keep the mock's order, got %+v", q.Options)
+	}
+
+	// Questions the user did not draw stay unseen after submitting, whatever the review policy.
+	submitted := mock.Access{Submitted: true, Attempted: []string{"q3", "q1"}}
+	hidden, ok := m.VisibleTo(candidate, submitted).(*mock.CandidateMock)
+	if !ok {
+		t.Fatal("expected answers to stay hidden under the never review policy")
+	}
+	if len(hidden.Questions) != 2 || hidden.QuestionCount != 2 || hidden.Questions[0].ID != "q1" || hidden.Questions[1].ID != "q3" {
+		t.Errorf("expected only the attempted questions in the mock's order, got %+v", hidden.Questions)
+	}
+	m.ReviewPolicy = entities.ReviewAfterSubmit
+	reviewed, ok := m.VisibleTo(candidate, submitted).(*mock.FullMock)
+	if !ok {
+		t.Fatal("expected answers after submitting under the after_submit review policy")
+	}
+	if len(reviewed.Questions) != 2 || reviewed.Questions[0].ID != "q1" || reviewed.Questions[1].ID != "q3" || reviewed.Questions[0].CorrectOptionID != "o1" {
+		t.Errorf("expected the attempted questions with their answers, got %+v", reviewed.Questions)
+	}
+	if len(m.Questions) != 4 {
+		t.Errorf("expected the mock itself to be left alone, got %d questions", len(m.Questions))
+	}
+	retaking := encode(m.VisibleTo(candidate, mock.Access{Submitted: true, InSession: true}))
+	for _, field := range answerKey {
+		if strings.Contains(retaking, field) {
+			t.Errorf("answer key leaked to a candidate taking the mock again through %s :: %s", field, retaking)
+		}
+	}
+}
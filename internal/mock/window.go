@@ -0,0 +1,45 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/errs"
+)
+
+var ErrOutsideWindow = errs.NewError(errors.New("mock is outside its availability window"), errs.DataErrorType, errs.ErrConflict)
+
+// The first layout carries its own offset, the others are read in the mock's timezone.
+var windowLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+func windowLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errs.NewError(fmt.Errorf("unknown timezone %q", timezone), errs.DataErrorType, errs.ErrDataIllegal)
+	}
+	return loc, nil
+}
+
+// Parse one side of a window. An empty value leaves that side open.
+func parseWindowTime(value string, loc *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range windowLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errs.NewError(fmt.Errorf("invalid window time %q", value), errs.DataErrorType, errs.ErrDataIllegal)
+}
+
+func checkWindow(opensAt *time.Time, closesAt *time.Time) error {
+	if opensAt != nil && closesAt != nil && !closesAt.After(*opensAt) {
+		return errs.NewError(errors.New("closes_at must be after opens_at"), errs.DataErrorType, errs.ErrDataIllegal)
+	}
+	return nil
+}
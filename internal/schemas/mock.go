@@ -4,20 +4,149 @@ type MockCreateRequest struct {
 	Topic string `json:"topic" validate:"required,min=1,max=200"`
 	Instructions string `json:"instructions" validate:"required,max=40000"`
 	TimeMins int `json:"time_mins" validate:"required,numeric,min=1"`
+	ReviewPolicy string `json:"review_policy" validate:"omitempty,oneof=never after_submit"`
+
+	// Scoring policy. NegativeMarking defaults to 1 (a wrong answer costs the question's points).
+	NegativeMarking *float64 `json:"negative_marking" validate:"omitempty,min=0,max=1"`
+	FloorAtZero bool `json:"floor_at_zero"`
+	PartialCredit bool `json:"partial_credit"`
+
+	Visibility string `json:"visibility" validate:"omitempty,oneof=public unlisted invite_only code"`
+	AccessCode string `json:"access_code" validate:"omitempty,min=4,max=64"` // Required when visibility is "code".
+
+	// Give each session its own order, seeded by the session ID.
+	ShuffleQuestions bool `json:"shuffle_questions"`
+	ShuffleOptions bool `json:"shuffle_options"`
+
+	// Zero means unlimited attempts, or no wait between them.
+	MaxAttempts int `json:"max_attempts" validate:"min=0"`
+	CooldownMins int `json:"cooldown_mins" validate:"min=0"`
+
+	// Availability window, RFC 3339. Times without an offset are read in Timezone, UTC when empty.
+	OpensAt string `json:"opens_at"`
+	ClosesAt string `json:"closes_at"`
+	Timezone string `json:"timezone" validate:"max=64"`
 	
-	Questions []MockQuestionSchema `json:"questions" validate:"required,min=1"`
+	Tags []string `json:"tags" validate:"max=20,dive,min=1,max=40"`
+	// A mock needs at least one question of its own or from the bank, see mock.ValidateMock.
+	Questions []MockQuestionSchema `json:"questions" validate:"dive"`
+	BankQuestions []MockBankQuestionSchema `json:"bank_questions" validate:"max=500,dive"`
+	Pools []MockPoolSchema `json:"pools" validate:"max=50,dive"`
 
 	AuthorID string `json:"author_id"`
 }
 
+// Each session draws Draw of the questions whose pool is Name.
+type MockPoolSchema struct {
+	Name string `json:"name" validate:"required,max=40"`
+	Draw int `json:"draw" validate:"required,min=1"`
+}
+
+// A question from the author's bank used by the mock. Points defaults to the bank question's own,
+// Position is shared with the mock's own questions.
+type MockBankQuestionSchema struct {
+	QuestionID string `json:"question_id" validate:"required"`
+	Points int `json:"points" validate:"min=0"`
+	Position int `json:"position" validate:"min=0"`
+	Pool string `json:"pool" validate:"max=40"`
+}
+
+// Options are checked against the question type by validateQuestion.
 type MockQuestionSchema struct {
+	Type string `json:"type" validate:"omitempty,oneof=single_choice multiple_select true_false numeric ordering matching"`
 	Problem string `json:"problem" validate:"required,min=1"`
 	Points int `json:"points" validate:"required,numeric,min=1"`
-	CorrectOptionID string `json:"correct_option_id" validate:"required,min=1"`
-	Options []MockOptionSchema `json:"options" validate:"required,min=4"`
+	CorrectOptionNumber int `json:"correct_option_number" validate:"min=0"` // Alternative to flagging the option with is_correct.
+	Explanation string `json:"explanation" validate:"max=40000"`
+	NegativePoints *float64 `json:"negative_points" validate:"omitempty,min=0"`
+	NumericAnswer *float64 `json:"numeric_answer"`
+	Tolerance float64 `json:"tolerance" validate:"min=0"`
+	Pool string `json:"pool" validate:"max=40"` // Name of one of the mock's pools, empty when always asked.
+	Options []MockOptionSchema `json:"options" validate:"dive"`
 }
 
 type MockOptionSchema struct {
 	Number int `json:"number" validate:"required,numeric,min=1"`
 	Option string `json:"option" validate:"required,min=1"`
-}
\ No newline at end of file
+	IsCorrect bool `json:"is_correct"`
+	Match string `json:"match" validate:"max=40000"`
+}
+// Nil fields are left untouched. When Questions is set it is the complete,
+// ordered list of questions: entries without an ID are added, existing
+// questions missing from it are removed. Options follow the same rule.
+// BankQuestions and Pools, when set, replace the links to bank questions and the pools.
+type MockUpdateRequest struct {
+	Topic *string `json:"topic" validate:"omitempty,min=1,max=200"`
+	Instructions *string `json:"instructions" validate:"omitempty,max=40000"`
+	TimeMins *int `json:"time_mins" validate:"omitempty,numeric,min=1"`
+	ReviewPolicy *string `json:"review_policy" validate:"omitempty,oneof=never after_submit"`
+
+	NegativeMarking *float64 `json:"negative_marking" validate:"omitempty,min=0,max=1"`
+	FloorAtZero *bool `json:"floor_at_zero"`
+	PartialCredit *bool `json:"partial_credit"`
+
+	Visibility *string `json:"visibility" validate:"omitempty,oneof=public unlisted invite_only code"`
+	AccessCode *string `json:"access_code" validate:"omitempty,min=4,max=64"`
+
+	ShuffleQuestions *bool `json:"shuffle_questions"`
+	ShuffleOptions *bool `json:"shuffle_options"`
+
+	MaxAttempts *int `json:"max_attempts" validate:"omitempty,min=0"`
+	CooldownMins *int `json:"cooldown_mins" validate:"omitempty,min=0"`
+
+	// An empty string removes that side of the window.
+	OpensAt *string `json:"opens_at"`
+	ClosesAt *string `json:"closes_at"`
+	Timezone *string `json:"timezone" validate:"omitempty,max=64"`
+
+	Tags *[]string `json:"tags" validate:"omitempty,max=20,dive,min=1,max=40"`
+	Questions *[]MockQuestionUpdateSchema `json:"questions" validate:"omitempty,dive"`
+	BankQuestions *[]MockBankQuestionSchema `json:"bank_questions" validate:"omitempty,max=500,dive"`
+	Pools *[]MockPoolSchema `json:"pools" validate:"omitempty,max=50,dive"`
+}
+
+type MockQuestionUpdateSchema struct {
+	ID string `json:"id"`
+	Type string `json:"type" validate:"omitempty,oneof=single_choice multiple_select true_false numeric ordering matching"`
+	Problem string `json:"problem" validate:"required,min=1"`
+	Points int `json:"points" validate:"required,numeric,min=1"`
+	CorrectOptionNumber int `json:"correct_option_number" validate:"min=0"` // Alternative to flagging the option with is_correct.
+	Explanation string `json:"explanation" validate:"max=40000"`
+	NegativePoints *float64 `json:"negative_points" validate:"omitempty,min=0"`
+	NumericAnswer *float64 `json:"numeric_answer"`
+	Tolerance float64 `json:"tolerance" validate:"min=0"`
+	Pool string `json:"pool" validate:"max=40"`
+	Options []MockOptionUpdateSchema `json:"options" validate:"dive"`
+}
+
+type MockOptionUpdateSchema struct {
+	ID string `json:"id"`
+	Number int `json:"number" validate:"required,numeric,min=1"`
+	Option string `json:"option" validate:"required,min=1"`
+	IsCorrect bool `json:"is_correct"`
+	Match string `json:"match" validate:"max=40000"`
+}
+
+type MockInvitationRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=500,dive,required"`
+}
+
+// Query parameters of GET /api/v1/mock. Tags are comma separated, a mock must carry all of them.
+// Dates are RFC 3339.
+type MockListRequest struct {
+	AuthorID string `query:"author_id"`
+	Status string `query:"status" validate:"omitempty,oneof=draft published closed"`
+	Topic string `query:"topic" validate:"max=200"`
+	Tags string `query:"tags"`
+	CreatedAfter string `query:"created_after"`
+	CreatedBefore string `query:"created_before"`
+	MinTimeMins int `query:"min_time_mins" validate:"min=0"`
+	MaxTimeMins int `query:"max_time_mins" validate:"min=0"`
+
+	Sort string `query:"sort" validate:"omitempty,oneof=created_at topic time_mins"`
+	Order string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Limit int `query:"limit" validate:"min=0,max=100"`
+	Cursor string `query:"cursor"`
+
+	ViewerID string `query:"-"` // Set by the handler, drafts of other authors are hidden.
+}
@@ -0,0 +1,109 @@
+package schemas
+
+import (
+	"strconv"
+
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/go-playground/validator"
+)
+
+func init() {
+	errs.RegisterStructValidation(func(sl validator.StructLevel) {
+		q := sl.Current().Interface().(MockQuestionSchema)
+
+		options := make([]questionOption, len(q.Options))
+		for i, opt := range q.Options {
+			options[i] = questionOption{Number: opt.Number, IsCorrect: opt.IsCorrect, Match: opt.Match}
+		}
+		validateQuestion(sl, q.Type, q.CorrectOptionNumber, q.NumericAnswer, options)
+	}, MockQuestionSchema{})
+
+	errs.RegisterStructValidation(func(sl validator.StructLevel) {
+		q := sl.Current().Interface().(MockQuestionUpdateSchema)
+
+		options := make([]questionOption, len(q.Options))
+		for i, opt := range q.Options {
+			options[i] = questionOption{Number: opt.Number, IsCorrect: opt.IsCorrect, Match: opt.Match}
+		}
+		validateQuestion(sl, q.Type, q.CorrectOptionNumber, q.NumericAnswer, options)
+	}, MockQuestionUpdateSchema{})
+}
+
+// The parts of an option that type-specific rules look at.
+type questionOption struct {
+	Number    int
+	IsCorrect bool
+	Match     string
+}
+
+// Type-specific rules shared by the create and update schemas.
+func validateQuestion(sl validator.StructLevel, typ string, correctOptionNumber int, numericAnswer *float64, options []questionOption) {
+	minOptions := func(n int) {
+		if len(options) < n {
+			sl.ReportError(options, "options", "Options", "min", strconv.Itoa(n))
+		}
+	}
+	// Single-answer questions name their correct option by number, or flag exactly one option.
+	requireCorrectOption := func() {
+		numbered, flagged := 0, 0
+		for _, opt := range options {
+			if opt.Number == correctOptionNumber {
+				numbered++
+			}
+			if opt.IsCorrect {
+				flagged++
+			}
+		}
+
+		switch {
+		case correctOptionNumber > 0 && numbered != 1:
+			sl.ReportError(correctOptionNumber, "correct_option_number", "CorrectOptionNumber", "exists", "")
+		case correctOptionNumber == 0 && flagged != 1:
+			sl.ReportError(options, "options", "Options", "one_correct", "")
+		}
+	}
+
+	switch typ {
+	case "", "single_choice":
+		minOptions(2)
+		requireCorrectOption()
+
+	case "true_false":
+		if len(options) != 2 {
+			sl.ReportError(options, "options", "Options", "len", "2")
+		}
+		requireCorrectOption()
+
+	case "multiple_select":
+		minOptions(2)
+
+		hasCorrect := false
+		for _, opt := range options {
+			hasCorrect = hasCorrect || opt.IsCorrect
+		}
+		if !hasCorrect {
+			sl.ReportError(options, "options", "Options", "has_correct", "")
+		}
+
+	case "numeric":
+		if len(options) != 0 {
+			sl.ReportError(options, "options", "Options", "len", "0")
+		}
+		if numericAnswer == nil {
+			sl.ReportError(numericAnswer, "numeric_answer", "NumericAnswer", "required", "")
+		}
+
+	case "ordering":
+		minOptions(2)
+
+	case "matching":
+		minOptions(2)
+
+		for _, opt := range options {
+			if opt.Match == "" {
+				sl.ReportError(options, "options", "Options", "has_match", "")
+				break
+			}
+		}
+	}
+}
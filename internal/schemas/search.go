@@ -0,0 +1,6 @@
+package schemas
+
+type SearchRequest struct {
+	Query string `query:"q" validate:"required,min=1,max=200"`
+	Limit int `query:"limit" validate:"min=0,max=50"`
+}
@@ -2,10 +2,16 @@ package schemas
 
 type SessionCreateRequest struct {
 	MockID string `json:"mock_id" validate:"required"`
+	AccessCode string `json:"access_code"` // For mocks protected by a code.
 }
 
+// Set the field matching the question type: option_id for single choice and true/false,
+// option_ids for multiple select and ordering (in order), value for numeric,
+// matches for matching ([K : option ID] [V : ID of the picked match, see mock.CandidateMatch]).
 type AnswerAddRequest struct {
-	MockID string `json:"mock_id" validate:"required"`
 	QuestionID string `json:"question_id" validate:"required"`
-	OptionID string `json:"option_id" validate:"required"`
-}
\ No newline at end of file
+	OptionID string `json:"option_id"`
+	OptionIDs []string `json:"option_ids"`
+	Value *float64 `json:"value"`
+	Matches map[string]string `json:"matches"`
+}
@@ -0,0 +1,214 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/errs"
+)
+
+const DefaultLimit = 20
+
+// Each index copies the searchable columns and is kept in sync by triggers,
+// so every write path to "mock", "mockQuestion" and the bank is covered.
+// A bank question is indexed once per mock linking it, links are only ever
+// inserted and deleted.
+var indexStmts = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS mockFTS USING fts5(mockID UNINDEXED, topic, instructions)`,
+	`CREATE TRIGGER IF NOT EXISTS mockFTS_insert AFTER INSERT ON mock BEGIN
+		INSERT INTO mockFTS (mockID, topic, instructions) VALUES (new.id, new.topic, new.instructions);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS mockFTS_update AFTER UPDATE OF topic, instructions ON mock BEGIN
+		DELETE FROM mockFTS WHERE mockID = old.id;
+		INSERT INTO mockFTS (mockID, topic, instructions) VALUES (new.id, new.topic, new.instructions);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS mockFTS_delete AFTER DELETE ON mock BEGIN
+		DELETE FROM mockFTS WHERE mockID = old.id;
+	END`,
+
+	`CREATE VIRTUAL TABLE IF NOT EXISTS questionFTS USING fts5(questionID UNINDEXED, mockID UNINDEXED, problem)`,
+	`CREATE TRIGGER IF NOT EXISTS questionFTS_insert AFTER INSERT ON mockQuestion BEGIN
+		INSERT INTO questionFTS (questionID, mockID, problem) VALUES (new.id, new.mockID, new.problem);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS questionFTS_update AFTER UPDATE OF problem ON mockQuestion BEGIN
+		DELETE FROM questionFTS WHERE questionID = old.id;
+		INSERT INTO questionFTS (questionID, mockID, problem) VALUES (new.id, new.mockID, new.problem);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS questionFTS_delete AFTER DELETE ON mockQuestion BEGIN
+		DELETE FROM questionFTS WHERE questionID = old.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS questionFTS_link AFTER INSERT ON mockBankQuestion BEGIN
+		INSERT INTO questionFTS (questionID, mockID, problem) SELECT id, new.mockID, problem FROM bankQuestion WHERE id = new.questionID;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS questionFTS_unlink AFTER DELETE ON mockBankQuestion BEGIN
+		DELETE FROM questionFTS WHERE questionID = old.questionID AND mockID = old.mockID;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS questionFTS_bankUpdate AFTER UPDATE OF problem ON bankQuestion BEGIN
+		DELETE FROM questionFTS WHERE questionID = old.id;
+		INSERT INTO questionFTS (questionID, mockID, problem) SELECT new.id, mockID, new.problem FROM mockBankQuestion WHERE questionID = new.id;
+	END`,
+}
+
+// Copy rows that were written before the indexes existed.
+var backfillStmts = []string{
+	`INSERT INTO mockFTS (mockID, topic, instructions) SELECT id, topic, instructions FROM mock`,
+	`INSERT INTO questionFTS (questionID, mockID, problem) SELECT id, mockID, problem FROM mockQuestion`,
+	`INSERT INTO questionFTS (questionID, mockID, problem) SELECT q.id, l.mockID, q.problem FROM mockBankQuestion l JOIN bankQuestion q ON q.id = l.questionID`,
+}
+
+type MockHit struct {
+	MockID  string  `json:"mock_id"`
+	Topic   string  `json:"topic"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+type QuestionHit struct {
+	QuestionID string  `json:"question_id"`
+	MockID     string  `json:"mock_id"`
+	Topic      string  `json:"topic"`
+	Snippet    string  `json:"snippet"`
+	Rank       float64 `json:"rank"`
+}
+
+type Results struct {
+	Mocks     []MockHit     `json:"mocks"`
+	Questions []QuestionHit `json:"questions"`
+}
+
+/*
+* Create the full-text indexes over mocks and their questions.
+* Must run after the "mock", "mockQuestion", "bankQuestion" and "mockBankQuestion" tables exist.
+* FTS5 is not compiled into go-sqlite3 by default, build with -tags sqlite_fts5.
+ */
+func Init(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("[pkg search : func Init] failed to begin transaction :: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'mockFTS'`).Scan(&existing)
+	if err != nil {
+		return fmt.Errorf("[pkg search : func Init] failed to inspect schema :: %w", err)
+	}
+
+	for _, stmt := range indexStmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("[pkg search : func Init] failed to create index :: %w", err)
+		}
+	}
+
+	if existing == 0 {
+		for _, stmt := range backfillStmts {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("[pkg search : func Init] backfill failed :: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("[pkg search : func Init] failed to commit :: %w", err)
+	}
+	return nil
+}
+
+// Search mock topics, instructions and question problems, best matches first.
+// Mocks are found under the same rules as mock.ListMocks: archived ones never,
+// drafts and mocks that are not public only by their author or, for the latter,
+// invited users. Questions stay hidden until a session starts, so only the
+// viewer's own mocks are searched for them.
+func Search(ctx context.Context, db *sql.DB, query string, viewerID string, limit int) (*Results, error) {
+	match := matchQuery(query)
+	if match == "" {
+		return nil, errs.NewError(errors.New("search query has no terms"), errs.DataErrorType, errs.ErrDataIllegal)
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	res := &Results{
+		Mocks:     []MockHit{},
+		Questions: []QuestionHit{},
+	}
+
+	mockStmt := `
+        SELECT m.id, m.topic, snippet(mockFTS, -1, '<mark>', '</mark>', '…', 16), bm25(mockFTS)
+        FROM mockFTS
+        JOIN mock m ON m.id = mockFTS.mockID
+        WHERE mockFTS MATCH ? AND m.archivedAt IS NULL
+            AND (m.status != 'draft' OR m.authorID = ?)
+            AND (m.visibility = 'public' OR m.authorID = ? OR m.id IN (SELECT mockID FROM mockInvitation WHERE userID = ?))
+        ORDER BY bm25(mockFTS)
+        LIMIT ?
+    `
+	rows, err := db.QueryContext(ctx, mockStmt, match, viewerID, viewerID, viewerID, limit)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	for rows.Next() {
+		var hit MockHit
+		if err := rows.Scan(&hit.MockID, &hit.Topic, &hit.Snippet, &hit.Rank); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		res.Mocks = append(res.Mocks, hit)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	questionStmt := `
+        SELECT questionFTS.questionID, m.id, m.topic, snippet(questionFTS, 2, '<mark>', '</mark>', '…', 16), bm25(questionFTS)
+        FROM questionFTS
+        JOIN mock m ON m.id = questionFTS.mockID
+        WHERE questionFTS MATCH ? AND m.archivedAt IS NULL AND m.authorID = ?
+        ORDER BY bm25(questionFTS)
+        LIMIT ?
+    `
+	rows, err = db.QueryContext(ctx, questionStmt, match, viewerID, limit)
+	if err != nil {
+		return nil, data.SQLiteErrorComparator(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hit QuestionHit
+		if err := rows.Scan(&hit.QuestionID, &hit.MockID, &hit.Topic, &hit.Snippet, &hit.Rank); err != nil {
+			return nil, err
+		}
+		res.Questions = append(res.Questions, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Turn free text into an FTS5 query matching every term, the last one as a prefix.
+// Quoting each term keeps user input from being read as FTS5 syntax.
+func matchQuery(query string) string {
+	terms := strings.Fields(query)
+
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.ReplaceAll(term, `"`, "")
+		if term == "" {
+			continue
+		}
+		quoted = append(quoted, `"`+term+`"`)
+	}
+	if len(quoted) == 0 {
+		return ""
+	}
+
+	quoted[len(quoted)-1] += "*"
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,219 @@
+//go:build sqlite_fts5
+
+package search_test
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/search"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+func insertMock(t *testing.T, db *sql.DB, id string, topic string, problems ...string) {
+	testutil.CreateUsers(t, db, "author")
+	now := time.Now()
+	_, err := db.Exec(`INSERT INTO mock (id, topic, instructions, timeMins, authorID, createdAt, lastUpdatedAt) VALUES (?, ?, '', 10, 'author', ?, ?)`, id, topic, now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range problems {
+		_, err := db.Exec(`INSERT INTO mockQuestion (id, problem, points, correctOptionID, mockID, createdAt, lastUpdatedAt) VALUES (?, ?, 1, '', ?, ?, ?)`, id+"-q"+string(rune('0'+i)), p, id, now, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+
+	// Written before the index exists, picked up by the backfill.
+	insertMock(t, db, "m1", "Organic chemistry", "Name the functional group of ethanol")
+
+	if err := search.Init(ctx, db); err != nil {
+		t.Fatalf("failed to init search :: %v", err)
+	}
+	if err := search.Init(ctx, db); err != nil {
+		t.Fatalf("init is not idempotent :: %v", err)
+	}
+
+	// Written after, picked up by the triggers.
+	insertMock(t, db, "m2", "Inorganic chemistry", "Balance the equation")
+
+	res, err := search.Search(ctx, db, "chem", "author", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Mocks) != 2 {
+		t.Fatalf("expected 2 mocks, got %+v", res.Mocks)
+	}
+
+	res, err = search.Search(ctx, db, `ethanol"`, "author", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Questions) != 1 || res.Questions[0].MockID != "m1" {
+		t.Fatalf("expected the ethanol question, got %+v", res.Questions)
+	}
+	if !strings.Contains(res.Questions[0].Snippet, "<mark>ethanol</mark>") {
+		t.Fatalf("expected a highlighted snippet, got %q", res.Questions[0].Snippet)
+	}
+
+	if _, err := db.Exec(`UPDATE mock SET topic = 'Physics' WHERE id = 'm2'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE mock SET archivedAt = ? WHERE id = 'm1'`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = search.Search(ctx, db, "chemistry", "author", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Mocks) != 0 || len(res.Questions) != 0 {
+		t.Fatalf("expected no hits, got %+v", res)
+	}
+
+	if _, err := search.Search(ctx, db, `""`, "author", 0); err == nil {
+		t.Fatal("expected an empty query to be rejected")
+	}
+}
+
+func TestSearchVisibility(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "guest")
+	if err := search.Init(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []struct{ id, status, visibility string }{
+		{"public", "published", "public"},
+		{"draft", "draft", "public"},
+		{"unlisted", "published", "unlisted"},
+		{"invited", "published", "invite_only"},
+	} {
+		insertMock(t, db, m.id, "Thermodynamics "+m.id, "State the second law of thermodynamics")
+		if _, err := db.Exec(`UPDATE mock SET status = ?, visibility = ? WHERE id = ?`, m.status, m.visibility, m.id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO mockInvitation (mockID, userID, createdAt) VALUES ('invited', 'guest', ?)`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		viewerID  string
+		mocks     []string
+		questions int
+	}{
+		{"author", []string{"draft", "invited", "public", "unlisted"}, 4},
+		{"guest", []string{"invited", "public"}, 0},
+		{"stranger", []string{"public"}, 0},
+	}
+	for _, tt := range tests {
+		res, err := search.Search(ctx, db, "thermodynamics", tt.viewerID, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mocks := make([]string, len(res.Mocks))
+		for i, hit := range res.Mocks {
+			mocks[i] = hit.MockID
+		}
+		slices.Sort(mocks)
+		if !slices.Equal(mocks, tt.mocks) {
+			t.Errorf("expected %s to find %v, got %v", tt.viewerID, tt.mocks, mocks)
+		}
+		if len(res.Questions) != tt.questions {
+			t.Errorf("expected %s to find %d questions, got %+v", tt.viewerID, tt.questions, res.Questions)
+		}
+	}
+}
+
+func TestSearchBankQuestions(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author")
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+
+	options := []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}}
+	banked, err := mock.CreateBankQuestion(ctx, db, author.ID, schemas.MockQuestionSchema{Problem: "Define entropy", Points: 1, CorrectOptionNumber: 1, Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(topic string) string {
+		created, err := mock.CreateMock(ctx, db, schemas.MockCreateRequest{
+			Topic: topic, Instructions: "i", TimeMins: 30, AuthorID: author.ID,
+			Questions:     []schemas.MockQuestionSchema{{Problem: "Own question", Points: 1, CorrectOptionNumber: 1, Options: options}},
+			BankQuestions: []schemas.MockBankQuestionSchema{{QuestionID: banked.ID, Points: 1}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created.ID
+	}
+
+	// Linked before the index exists, picked up by the backfill.
+	first := create("First")
+	if err := search.Init(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	second := create("Second")
+
+	found := func(query string) []string {
+		res, err := search.Search(ctx, db, query, author.ID, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mocks := make([]string, 0, len(res.Questions))
+		for _, hit := range res.Questions {
+			if hit.QuestionID != banked.ID {
+				t.Errorf("expected only the bank question, got %+v", hit)
+			}
+			mocks = append(mocks, hit.MockID)
+		}
+		slices.Sort(mocks)
+		return mocks
+	}
+	both := []string{first, second}
+	slices.Sort(both)
+
+	if got := found("entropy"); !slices.Equal(got, both) {
+		t.Fatalf("expected the bank question in both mocks, got %v", got)
+	}
+
+	current, err := mock.GetBankQuestion(ctx, db, banked.ID, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := schemas.MockQuestionUpdateSchema{Problem: "Define enthalpy", Points: 1}
+	for _, opt := range current.Options {
+		update.Options = append(update.Options, schemas.MockOptionUpdateSchema{ID: opt.ID, Number: opt.Number, Option: opt.Option, IsCorrect: opt.IsCorrect})
+	}
+	if _, err := mock.UpdateBankQuestion(ctx, db, banked.ID, author.ID, update, mock.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := found("entropy"); len(got) != 0 {
+		t.Errorf("expected the old wording to be gone, got %v", got)
+	}
+	if got := found("enthalpy"); !slices.Equal(got, both) {
+		t.Errorf("expected the new wording in both mocks, got %v", got)
+	}
+
+	none := []schemas.MockBankQuestionSchema{}
+	if _, err := mock.UpdateMock(ctx, db, first, author.ID, schemas.MockUpdateRequest{BankQuestions: &none}, mock.UpdateOptions{AllowStructural: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := found("enthalpy"); !slices.Equal(got, []string{second}) {
+		t.Errorf("expected the question only where it is still linked, got %v", got)
+	}
+}
@@ -14,7 +14,14 @@ var ProtectedRoutes = []string{
 	"/api/v1/auth/protected",
 
 	"/api/v1/mock",
+	"/api/v1/mock/*",
 	"/api/v1/session",
+	"/api/v1/session/*",
+	"/api/v1/attempt",
+	"/api/v1/attempt/*",
+	"/api/v1/search",
+	"/api/v1/bank",
+	"/api/v1/bank/*",
 }
 
 type WebServer struct {
@@ -34,7 +41,10 @@ func NewWebServer() *WebServer {
 		return nil
 	}
 
-	su.Init()
+	if err := su.Init(); err != nil {
+		slog.Error("[func NewWebServer] init failed ", "error", err)
+		return nil
+	}
 
 	authMiddleware := auth.New(auth.Config{
 		DB:      su.SQLite.DB,
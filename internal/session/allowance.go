@@ -0,0 +1,35 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+)
+
+// Enforce the mock's attempt limit and cooldown against the user's submitted attempts.
+func (s *SessionManager) checkAllowance(ctx context.Context, m entities.Mock, userID string, now time.Time) error {
+	if m.MaxAttempts == 0 && m.CooldownMins == 0 {
+		return nil
+	}
+
+	count, last, err := attempt.History(ctx, s.DB, userID, m.ID)
+	if err != nil {
+		return err
+	}
+
+	if m.MaxAttempts > 0 && count >= m.MaxAttempts {
+		return ErrAttemptLimit
+	}
+
+	if m.CooldownMins > 0 && last != nil {
+		next := last.Add(time.Duration(m.CooldownMins) * time.Minute)
+		if now.Before(next) {
+			return errs.NewError(fmt.Errorf("next attempt allowed at %s", next.UTC().Format(time.RFC3339)), errs.DataErrorType, errs.ErrLimitReached)
+		}
+	}
+	return nil
+}
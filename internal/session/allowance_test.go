@@ -0,0 +1,59 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/session"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+// Refusals happen before Redis is touched, so the manager runs without it.
+func TestAttemptAllowance(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author", "candidate")
+	manager := session.NewSessionManager(db, nil)
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+
+	publish := func(maxAttempts int, cooldownMins int) string {
+		m, err := mock.CreateMock(ctx, db, schemas.MockCreateRequest{
+			Topic: "Certification", Instructions: "i", TimeMins: 30, AuthorID: author.ID,
+			MaxAttempts: maxAttempts, CooldownMins: cooldownMins,
+			Questions: []schemas.MockQuestionSchema{{Problem: "p", Points: 1, CorrectOptionNumber: 1, Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mock.SetStatus(ctx, db, m.ID, author, entities.MockPublished); err != nil {
+			t.Fatal(err)
+		}
+
+		now := time.Now()
+		att := entities.Attempt{ID: m.ID + "-attempt", SessionID: m.ID + "-session", MockID: m.ID, UserID: "candidate", MaxMarks: 1, StartedAt: now.Add(-time.Minute), SubmittedAt: now}
+		if _, err := attempt.CreateAttempt(ctx, db, att, nil); err != nil {
+			t.Fatal(err)
+		}
+		return m.ID
+	}
+
+	limitReached := errs.Error{Code: errs.ErrLimitReached, Type: errs.DataErrorType.String()}
+
+	_, err := manager.New(ctx, publish(1, 0), "candidate", "")
+	if !errors.Is(err, session.ErrAttemptLimit) {
+		t.Fatalf("expected the attempt limit, got %v", err)
+	}
+
+	_, err = manager.New(ctx, publish(0, 60), "candidate", "")
+	if !errors.Is(err, limitReached) || !strings.Contains(err.Error(), "next attempt allowed at") {
+		t.Fatalf("expected the cooldown, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"context"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/redis/go-redis/v9"
+)
+
+// Answers of a session, [K : questionID] [V : encoded response].
+// Kept apart from the session JSON so that every answer is a single atomic HSET.
+func answersKey(sessionID string) string {
+	return sessionKey(sessionID) + ":answers"
+}
+
+// Record one answer only while the session exists, and give the answers
+// hash the same remaining lifetime as the session.
+//
+// KEYS[1] session key, KEYS[2] answers key
+// ARGV[1] question ID, ARGV[2] encoded response
+var recordAnswerScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl == -2 then
+	return 0
+end
+
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[2], ttl)
+end
+return 1
+`)
+
+func (s *SessionManager) recordAnswer(ctx context.Context, sessionID string, questionID string, response []string) error {
+	keys := []string{sessionKey(sessionID), answersKey(sessionID)}
+
+	recorded, err := recordAnswerScript.Run(ctx, s.Redis.Client, keys, questionID, encodeResponse(response)).Int()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return err
+	}
+	if recorded == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *SessionManager) getAnswers(ctx context.Context, sessionID string) (map[string][]string, error) {
+	values, err := s.Redis.Client.HGetAll(ctx, answersKey(sessionID)).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return nil, err
+	}
+
+	answers := make(map[string][]string, len(values))
+	for questionID, value := range values {
+		answers[questionID] = decodeResponse(value)
+	}
+	return answers, nil
+}
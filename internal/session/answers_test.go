@@ -0,0 +1,62 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/session"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+func TestRecordAnswer(t *testing.T) {
+	ctx := context.Background()
+	rdb := createTestRedis(t)
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "candidate")
+	manager := session.NewSessionManager(db, rdb)
+
+	mockID, q := publishTestMock(t, db)
+	ses, err := manager.New(ctx, mockID, "candidate", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rdb.Client.Del(ctx, session.SessionKey(ses.ID), session.AnswersKey(ses.ID)) })
+
+	other := q.Options[0].ID
+	if other == q.CorrectOptionID {
+		other = q.Options[1].ID
+	}
+	for _, optionID := range []string{other, q.CorrectOptionID} {
+		if err := manager.AddAnswer(ctx, ses.ID, "candidate", q.ID, []string{optionID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := manager.Get(ctx, ses.ID, "candidate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer := got.Answers[q.ID]; len(got.Answers) != 1 || len(answer) != 1 || answer[0] != q.CorrectOptionID {
+		t.Errorf("expected the last answer to replace the first, got %v", got.Answers)
+	}
+
+	sessionTTL := rdb.Client.PTTL(ctx, session.SessionKey(ses.ID)).Val()
+	answersTTL := rdb.Client.PTTL(ctx, session.AnswersKey(ses.ID)).Val()
+	if sessionTTL <= 0 || answersTTL <= 0 || (sessionTTL-answersTTL).Abs() > time.Second {
+		t.Errorf("expected the answers to expire with the session, got %v and %v", answersTTL, sessionTTL)
+	}
+
+	// The session expires between AddAnswer loading it and the answer being written.
+	if err := rdb.Client.Del(ctx, session.SessionKey(ses.ID), session.AnswersKey(ses.ID)).Err(); err != nil {
+		t.Fatal(err)
+	}
+	err = session.RecordAnswer(manager, ctx, ses.ID, q.ID, []string{q.CorrectOptionID})
+	if !errors.Is(err, session.ErrSessionNotFound) {
+		t.Fatalf("expected the answer to be refused once the session is gone, got %v", err)
+	}
+	if n := rdb.Client.Exists(ctx, session.AnswersKey(ses.ID)).Val(); n != 0 {
+		t.Error("expected no answers hash to outlive its session")
+	}
+}
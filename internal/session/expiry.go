@@ -0,0 +1,106 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+// Sorted set of session IDs, scored by the unix time after which they are auto-submitted.
+const deadlinesKey = "session:deadlines"
+
+const ExpiryScanInterval = 5 * time.Second
+
+// Sessions claimed per scan, the rest are picked up on the next tick.
+const expiryBatchSize = 100
+
+// Grade and persist sessions whose deadline (plus grace period) has passed,
+// until ctx is cancelled.
+func (s *SessionManager) RunExpiryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.submitExpired(ctx, time.Now()); err != nil {
+				slog.Error("[pkg session : func RunExpiryWorker] scan failed", "error", err)
+			}
+		}
+	}
+}
+
+func (s *SessionManager) submitExpired(ctx context.Context, now time.Time) error {
+	ids, err := s.Redis.Client.ZRangeByScore(ctx, deadlinesKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.Unix(), 10),
+		Count: expiryBatchSize,
+	}).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return err
+	}
+
+	for _, sessionID := range ids {
+		// Only the instance that manages to remove the entry grades the session.
+		removed, err := s.Redis.Client.ZRem(ctx, deadlinesKey, sessionID).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+
+		if err := s.submitExpiredSession(ctx, sessionID); err != nil {
+			slog.Error("[pkg session : func submitExpired] auto-submission failed", "session_id", sessionID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *SessionManager) submitExpiredSession(ctx context.Context, sessionID string) error {
+	ses, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.RedisErrorType.String()}) {
+			// Already submitted, or retention ran out.
+			return nil
+		}
+		return s.retryExpired(ctx, sessionID, err)
+	}
+
+	mck, err := s.loadMock(ctx, ses.MockID)
+	if err != nil {
+		if errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.DataErrorType.String()}) {
+			return err
+		}
+		return s.retryExpired(ctx, sessionID, err)
+	}
+
+	att, err := s.submit(ctx, ses, mck)
+	if err != nil {
+		if errors.Is(err, errs.Error{Code: errs.ErrAlreadyExists, Type: errs.SQLErrorType.String()}) {
+			// Submitted concurrently through the API.
+			return nil
+		}
+		return s.retryExpired(ctx, sessionID, err)
+	}
+
+	slog.Info("Session auto-submitted", "session_id", ses.ID, "attempt_id", att.ID, "total_marks", att.TotalMarks)
+	return nil
+}
+
+// Put the session back in the queue so a transient failure does not lose the attempt.
+func (s *SessionManager) retryExpired(ctx context.Context, sessionID string, cause error) error {
+	err := s.Redis.Client.ZAdd(ctx, deadlinesKey, redis.Z{
+		Score:  float64(time.Now().Add(ExpiryScanInterval).Unix()),
+		Member: sessionID,
+	}).Err()
+	if err != nil {
+		return errors.Join(cause, data.RedisErrorComparator(err))
+	}
+	return cause
+}
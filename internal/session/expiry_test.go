@@ -0,0 +1,185 @@
+package session_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/session"
+	"github.com/ashtonx86/mocker/internal/testutil"
+	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis from .env.test, like the tests of the data package. Tests needing it are
+// skipped when no instance is configured.
+func createTestRedis(t *testing.T) *data.Redis {
+	// Please for god's sake, do not use a production instance.
+	godotenv.Load(filepath.Join("..", "..", ".env.test"))
+
+	connString := os.Getenv("REDIS_CONN_STRING")
+	if connString == "" {
+		t.Skip("REDIS_CONN_STRING is not set")
+	}
+	rdb, err := data.NewRedis(connString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rdb.Client.Close() })
+
+	if err := rdb.Client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("redis is unreachable :: %v", err)
+	}
+	return rdb
+}
+
+// A published mock with one single choice question, and the ID of its correct option.
+func publishTestMock(t *testing.T, db *sql.DB) (string, mock.FullMockQuestion) {
+	ctx := context.Background()
+	author := &entities.User{ID: "author", Role: entities.RoleUser}
+	testutil.CreateUsers(t, db, author.ID)
+
+	m, err := mock.CreateMock(ctx, db, schemas.MockCreateRequest{
+		Topic: "Expiry", Instructions: "i", TimeMins: 30, AuthorID: author.ID,
+		Questions: []schemas.MockQuestionSchema{{Problem: "p", Points: 1, CorrectOptionNumber: 2, Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mock.SetStatus(ctx, db, m.ID, author, entities.MockPublished); err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := mock.GetMock(ctx, db, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.ID, full.Questions[0]
+}
+
+func TestSubmitExpired(t *testing.T) {
+	ctx := context.Background()
+	rdb := createTestRedis(t)
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "c1", "c2", "c3")
+	manager := session.NewSessionManager(db, rdb)
+
+	mockID, q := publishTestMock(t, db)
+	pastDeadline := time.Now().Add(31 * time.Minute)
+
+	attempts := func(userID string) []entities.Attempt {
+		attempts, err := attempt.ListAttempts(ctx, db, userID, mockID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return attempts
+	}
+	queued := func(sessionID string) bool {
+		err := rdb.Client.ZScore(ctx, session.DeadlinesKey, sessionID).Err()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	start := func(userID string) string {
+		ses, err := manager.New(ctx, mockID, userID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !queued(ses.ID) {
+			t.Fatalf("expected session %s to be queued for expiry", ses.ID)
+		}
+		return ses.ID
+	}
+
+	t.Run("graded once however many workers claim it", func(t *testing.T) {
+		sessionID := start("c1")
+		if err := manager.AddAnswer(ctx, sessionID, "c1", q.ID, []string{q.CorrectOptionID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := session.SubmitExpired(manager, ctx, time.Now()); err != nil || len(attempts("c1")) != 0 {
+			t.Fatalf("expected nothing submitted before the deadline, got %v (%v)", attempts("c1"), err)
+		}
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := session.SubmitExpired(manager, ctx, pastDeadline); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		got := attempts("c1")
+		if len(got) != 1 || got[0].SessionID != sessionID || got[0].TotalMarks != 1 {
+			t.Fatalf("expected one graded attempt for the session, got %+v", got)
+		}
+		if _, err := manager.Get(ctx, sessionID, "c1"); !errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.RedisErrorType.String()}) {
+			t.Errorf("expected the session to be gone, got %v", err)
+		}
+		if queued(sessionID) {
+			t.Error("expected the session to leave the queue")
+		}
+	})
+
+	t.Run("requeued when grading fails", func(t *testing.T) {
+		sessionID := start("c2")
+
+		closed, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "closed.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		closed.Close()
+		broken := session.NewSessionManager(closed, rdb)
+
+		if err := session.SubmitExpired(broken, ctx, pastDeadline); err != nil {
+			t.Fatal(err)
+		}
+		if len(attempts("c2")) != 0 || !queued(sessionID) {
+			t.Fatal("expected the session back in the queue after a failed submission")
+		}
+
+		if err := session.SubmitExpired(manager, ctx, pastDeadline.Add(session.ExpiryScanInterval)); err != nil {
+			t.Fatal(err)
+		}
+		if got := attempts("c2"); len(got) != 1 || got[0].SessionID != sessionID {
+			t.Fatalf("expected the retry to submit the session, got %+v", got)
+		}
+	})
+
+	t.Run("left alone when submitted through the API first", func(t *testing.T) {
+		sessionID := start("c3")
+
+		// Submit has stored the attempt but not yet cleared the session from Redis.
+		now := time.Now()
+		att := entities.Attempt{ID: sessionID + "-attempt", SessionID: sessionID, MockID: mockID, UserID: "c3", MaxMarks: 1, StartedAt: now, SubmittedAt: now}
+		if _, err := attempt.CreateAttempt(ctx, db, att, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := session.SubmitExpired(manager, ctx, pastDeadline); err != nil {
+			t.Fatal(err)
+		}
+		if got := attempts("c3"); len(got) != 1 || got[0].ID != att.ID {
+			t.Fatalf("expected only the attempt submitted through the API, got %+v", got)
+		}
+		if queued(sessionID) {
+			t.Error("expected the session not to be retried")
+		}
+	})
+}
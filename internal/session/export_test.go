@@ -0,0 +1,13 @@
+package session
+
+// Unexported parts of the package used by the tests in session_test.
+
+const DeadlinesKey = deadlinesKey
+
+var (
+	SessionKey = sessionKey
+	AnswersKey = answersKey
+
+	SubmitExpired = (*SessionManager).submitExpired
+	RecordAnswer  = (*SessionManager).recordAnswer
+)
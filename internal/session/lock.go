@@ -0,0 +1,90 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// How long an edit lock outlives a holder that never releases it.
+const MockLockTTL = 30 * time.Second
+
+var (
+	ErrMockLocked  = errs.NewError(errors.New("mock is being edited, try again"), errs.DataErrorType, errs.ErrConflict)
+	ErrMockChanged = errs.NewError(errors.New("mock changed while the session was starting, try again"), errs.DataErrorType, errs.ErrConflict)
+)
+
+// Held while a mock is edited in a way sessions must not start across, see LockMocks.
+func mockLockKey(mockID string) string {
+	return "mock:" + mockID + ":lock"
+}
+
+// Release a lock only while it still holds the token it was taken with.
+//
+// KEYS[1] lock key
+// ARGV[1] token
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Take the edit lock of every mock, or none of them.
+// While it is held, sessions already running can be counted with ActiveSessions
+// and no new one starts, so the count holds until the returned func releases it.
+// A lock taken by someone else is not waited for, ErrMockLocked is returned instead.
+func (s *SessionManager) LockMocks(ctx context.Context, mockIDs ...string) (func(), error) {
+	token := uuid.NewString()
+	locked := make([]string, 0, len(mockIDs))
+
+	unlock := func() {
+		// Released even when the caller's context is done, rather than waiting out the TTL.
+		ctx := context.WithoutCancel(ctx)
+		for _, id := range locked {
+			unlockScript.Run(ctx, s.Redis.Client, []string{mockLockKey(id)}, token)
+		}
+	}
+
+	for _, id := range mockIDs {
+		ok, err := s.Redis.Client.SetNX(ctx, mockLockKey(id), token, MockLockTTL).Result()
+		if err = data.RedisErrorComparator(err); err != nil {
+			unlock()
+			return nil, err
+		}
+		if !ok {
+			unlock()
+			return nil, ErrMockLocked
+		}
+		locked = append(locked, id)
+	}
+	return unlock, nil
+}
+
+// Check, once a session is registered on the mock, that the copy it was built
+// from is still current. An edit holding the lock may have counted the sessions
+// before this one, an edit that already released it left a newer version behind.
+func (s *SessionManager) checkMockUnchanged(ctx context.Context, d *mock.FullMock) error {
+	locked, err := s.Redis.Client.Exists(ctx, mockLockKey(d.ID)).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return err
+	}
+	if locked > 0 {
+		return ErrMockLocked
+	}
+
+	current, err := mock.GetMockMeta(ctx, s.DB, d.ID)
+	if err != nil {
+		return err
+	}
+	if !current.LastUpdatedAt.Equal(d.LastUpdatedAt) {
+		return ErrMockChanged
+	}
+	return nil
+}
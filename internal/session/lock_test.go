@@ -0,0 +1,98 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/session"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+func TestMockLock(t *testing.T) {
+	ctx := context.Background()
+	rdb := createTestRedis(t)
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "candidate")
+	manager := session.NewSessionManager(db, rdb)
+
+	mockID, _ := publishTestMock(t, db)
+
+	active := func() int {
+		n, err := manager.ActiveSessions(ctx, mockID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	unlock, err := manager.LockMocks(ctx, mockID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.LockMocks(ctx, mockID); !errors.Is(err, session.ErrMockLocked) {
+		t.Fatalf("expected a held lock to be refused, got %v", err)
+	}
+	if _, err := manager.New(ctx, mockID, "candidate", ""); !errors.Is(err, session.ErrMockLocked) {
+		t.Fatalf("expected no session to start during an edit, got %v", err)
+	}
+	if n := active(); n != 0 {
+		t.Fatalf("expected the refused session to be discarded, got %d active", n)
+	}
+	unlock()
+
+	// A copy loaded before an edit committed.
+	manager.Mocks = mock.NewCache(db, nil)
+	if _, err := manager.Mocks.Get(ctx, mockID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE mock SET lastUpdatedAt = ? WHERE id = ?`, time.Now(), mockID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.New(ctx, mockID, "candidate", ""); !errors.Is(err, session.ErrMockChanged) {
+		t.Fatalf("expected a session built from an outdated mock to be refused, got %v", err)
+	}
+	if n := active(); n != 0 {
+		t.Fatalf("expected the refused session to be discarded, got %d active", n)
+	}
+
+	if err := manager.Mocks.Invalidate(ctx, mockID); err != nil {
+		t.Fatal(err)
+	}
+	ses, err := manager.New(ctx, mockID, "candidate", "")
+	if err != nil {
+		t.Fatalf("expected the session to start once the edit is done, got %v", err)
+	}
+	t.Cleanup(func() { rdb.Client.Del(ctx, session.SessionKey(ses.ID)) })
+	if n := active(); n != 1 {
+		t.Fatalf("expected one active session, got %d", n)
+	}
+}
+
+func TestMockDeletedWhileStarting(t *testing.T) {
+	ctx := context.Background()
+	rdb := createTestRedis(t)
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "candidate")
+	manager := session.NewSessionManager(db, rdb)
+	manager.Mocks = mock.NewCache(db, nil)
+
+	mockID, _ := publishTestMock(t, db)
+	if _, err := manager.Mocks.Get(ctx, mockID); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.DeleteMock(ctx, db, mockID, &entities.User{ID: "author", Role: entities.RoleUser}, mock.DeleteOptions{Hard: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.New(ctx, mockID, "candidate", ""); !errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.DataErrorType.String()}) {
+		t.Fatalf("expected no session on a deleted mock, got %v", err)
+	}
+	if n, err := manager.ActiveSessions(ctx, mockID); err != nil || n != 0 {
+		t.Fatalf("expected the refused session to be discarded, got %d (%v)", n, err)
+	}
+}
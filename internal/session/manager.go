@@ -4,45 +4,139 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"time"
 
+	"github.com/ashtonx86/mocker/internal/attempt"
 	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/entities"
 	"github.com/ashtonx86/mocker/internal/errs"
 	"github.com/ashtonx86/mocker/internal/mock"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// How long a session outlives its grace period in Redis, so it can still be submitted.
+const SessionRetention = 10 * time.Minute
+
+const DefaultGracePeriod = 30 * time.Second
+
+var (
+	ErrSessionExpired  = errs.NewError(errors.New("session deadline has passed"), errs.DataErrorType, errs.ErrExpired)
+	ErrSessionNotFound = errs.NewError(errors.New("session not found"), errs.DataErrorType, errs.ErrNotFound)
+	ErrSessionExists   = errs.NewError(errors.New("an active session for this mock already exists"), errs.DataErrorType, errs.ErrAlreadyExists)
+	ErrAttemptLimit    = errs.NewError(errors.New("no attempts left at this mock"), errs.DataErrorType, errs.ErrLimitReached)
 )
 
 type SessionManager struct {
 	DB    *sql.DB
 	Redis *data.Redis
+
+	// Late answers are still accepted for this long after the deadline,
+	// to absorb network latency between the client and the server.
+	GracePeriod time.Duration
+
+	// Builds the scorer that grades sessions of a mock.
+	ScorerFor func(m entities.Mock) Scorer
+
+	// Serves mocks to New, grading and results. Nil loads them straight from SQLite.
+	Mocks *mock.Cache
 }
 
 type AnswerResult struct {
-    QuestionID    string
-    SelectedOption string
-    IsCorrect     bool
+	QuestionID      string   `json:"question_id"`
+	Problem         string   `json:"problem,omitempty"`
+	SelectedOption  string   `json:"selected_option"` // Set for single-option answers only.
+	Response        []string `json:"response,omitempty"`
+	CorrectOption   string   `json:"correct_option,omitempty"`
+	CorrectResponse []string `json:"correct_response,omitempty"`
+	IsCorrect       bool     `json:"is_correct"`
+	Points          float64  `json:"points"`
+	Explanation     string   `json:"explanation,omitempty"`
 }
 
 func NewSessionManager(db *sql.DB, redisClient *data.Redis) *SessionManager {
 	return &SessionManager{
 		DB:    db,
 		Redis: redisClient,
+
+		GracePeriod: DefaultGracePeriod,
+		ScorerFor:   NewPolicyScorer,
+	}
+}
+
+// The session itself, stored as JSON.
+// Braces make it a Redis Cluster hash tag, so every key of one session lands on the same slot.
+func sessionKey(sessionID string) string {
+	return "session:{" + sessionID + "}"
+}
+
+// Index of a user's active sessions, [K : mockID] [V : sessionID].
+func userSessionsKey(userID string) string {
+	return "user:" + userID + ":sessions"
+}
+
+// Sessions currently running on a mock.
+func mockSessionsKey(mockID string) string {
+	return "mock:" + mockID + ":sessions"
+}
+
+func (s *SessionManager) loadMock(ctx context.Context, mockID string) (*mock.FullMock, error) {
+	if s.Mocks != nil {
+		return s.Mocks.Get(ctx, mockID)
 	}
+	return mock.GetMock(ctx, s.DB, mockID)
 }
 
-// Create new session
-func (s *SessionManager) New(ctx context.Context, mockID string, userID string) (map[string]Session, error) {
+// Create new session.
+// A user may hold several active sessions, but only one per mock.
+// The mock must be published and within its availability window,
+// and the user must have an attempt left and be past any cooldown.
+// Mocks that are not public also need an invitation or their access code.
+// The questions of the session are drawn from the mock's pools and put in its own order here,
+// see DrawLayout and Shuffle. Ordering questions never list their options in the order that solves them.
+func (s *SessionManager) New(ctx context.Context, mockID string, userID string, accessCode string) (*SessionState, error) {
+	d, err := s.loadMock(ctx, mockID)
+	if err != nil {
+		return nil, err
+	}
+	switch d.Status {
+	case entities.MockArchived:
+		return nil, mock.ErrMockArchived
+	case entities.MockDraft, entities.MockClosed:
+		return nil, mock.ErrMockNotPublished
+	}
+	if err := mock.CheckAccess(ctx, s.DB, &d.Mock, userID, accessCode); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !d.OpenAt(now) {
+		return nil, mock.ErrOutsideWindow
+	}
+	if err := s.checkAllowance(ctx, d.Mock, userID, now); err != nil {
+		return nil, err
+	}
+
 	ses := Session{
 		ID:     uuid.NewString(),
 		MockID: mockID,
 		UserID: userID,
 
-		CreatedAt: time.Now(),
+		OptionsShuffled: d.ShuffleOptions,
+
+		StartedAt:  now,
+		DeadlineAt: now.Add(time.Duration(d.TimeMins) * time.Minute),
+		CreatedAt:  now,
 	}
-	identifier := userID
+	ses.Questions = Shuffle(DrawLayout(d), ses.ID, d.ShuffleQuestions, d.ShuffleOptions)
 
-	d, err := mock.GetMock(ctx, s.DB, mockID)
-	if err != nil {
+	// Sessions started late in the window get less time, everyone stops at closes_at.
+	if d.ClosesAt != nil && ses.DeadlineAt.After(*d.ClosesAt) {
+		ses.DeadlineAt = *d.ClosesAt
+	}
+
+	if err := s.claimMockSlot(ctx, ses); err != nil {
 		return nil, err
 	}
 
@@ -50,117 +144,288 @@ func (s *SessionManager) New(ctx context.Context, mockID string, userID string)
 	if err != nil {
 		return nil, errs.NewError(err, errs.DataErrorType, errs.ErrUndefined)
 	}
-	
-	err = s.Redis.Client.Set(ctx, identifier, sesH, time.Duration(d.TimeMins)).Err()
-	err = data.RedisErrorComparator(err) 
+
+	_, err = s.Redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(ses.ID), sesH, s.expiration(ses, now))
+		pipe.SAdd(ctx, mockSessionsKey(mockID), ses.ID)
+		pipe.ZAdd(ctx, deadlinesKey, redis.Z{
+			Score:  float64(ses.DeadlineAt.Add(s.GracePeriod).Unix()),
+			Member: ses.ID,
+		})
+		return nil
+	})
+	err = data.RedisErrorComparator(err)
 
 	if err != nil {
+		s.Redis.Client.HDel(ctx, userSessionsKey(userID), mockID)
 		return nil, err
 	}
 
-	return map[string]Session{
-		identifier: ses,
-	}, nil
+	// Registered first and checked after, so that an edit either sees the session or is seen by it.
+	if err := s.checkMockUnchanged(ctx, d); err != nil {
+		s.discard(ctx, ses)
+		return nil, err
+	}
+
+	state := ses.State(now)
+	return &state, nil
 }
 
-func (s *SessionManager) AddAnswer(ctx context.Context, mockID string, userID string, questionID string, optionID string) error {
-	b, err := s.Redis.Client.Get(ctx, userID).Bytes()
-	err = data.RedisErrorComparator(err)
+// Reserve the user's slot for this mock in the index.
+// A slot whose session has already expired from Redis is reclaimed.
+func (s *SessionManager) claimMockSlot(ctx context.Context, ses Session) error {
+	indexKey := userSessionsKey(ses.UserID)
+
+	ok, err := s.Redis.Client.HSetNX(ctx, indexKey, ses.MockID, ses.ID).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	existingID, err := s.Redis.Client.HGet(ctx, indexKey, ses.MockID).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return err
+	}
+
+	exists, err := s.Redis.Client.Exists(ctx, sessionKey(existingID)).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return ErrSessionExists
+	}
+
+	err = s.Redis.Client.HSet(ctx, indexKey, ses.MockID, ses.ID).Err()
+	return data.RedisErrorComparator(err)
+}
+
+// List the user's active sessions, dropping index entries whose session is gone.
+func (s *SessionManager) List(ctx context.Context, userID string) ([]SessionState, error) {
+	indexKey := userSessionsKey(userID)
+
+	index, err := s.Redis.Client.HGetAll(ctx, indexKey).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	states := []SessionState{}
+
+	for mockID, sessionID := range index {
+		ses, err := s.getSession(ctx, sessionID)
+		if errors.Is(err, errs.Error{Code: errs.ErrNotFound, Type: errs.RedisErrorType.String()}) {
+			s.Redis.Client.HDel(ctx, indexKey, mockID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, ses.State(now))
+	}
+
+	return states, nil
+}
+
+// Count the sessions running on a mock, dropping entries whose session is gone.
+func (s *SessionManager) ActiveSessions(ctx context.Context, mockID string) (int, error) {
+	key := mockSessionsKey(mockID)
+
+	ids, err := s.Redis.Client.SMembers(ctx, key).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		return 0, err
+	}
+
+	active := 0
+	for _, id := range ids {
+		exists, err := s.Redis.Client.Exists(ctx, sessionKey(id)).Result()
+		if err = data.RedisErrorComparator(err); err != nil {
+			return 0, err
+		}
+		if exists == 0 {
+			s.Redis.Client.SRem(ctx, key, id)
+			continue
+		}
+		active++
+	}
+	return active, nil
+}
+
+// Whether the user has a session running on the mock.
+func (s *SessionManager) InSession(ctx context.Context, userID string, mockID string) (bool, error) {
+	ses, err := s.Find(ctx, userID, mockID)
+	return ses != nil, err
+}
+
+// The session the user has running on the mock, nil when there is none.
+func (s *SessionManager) Find(ctx context.Context, userID string, mockID string) (*Session, error) {
+	notFound := errs.Error{Code: errs.ErrNotFound, Type: errs.RedisErrorType.String()}
 
+	sessionID, err := s.Redis.Client.HGet(ctx, userSessionsKey(userID), mockID).Result()
+	if err = data.RedisErrorComparator(err); err != nil {
+		if errors.Is(err, notFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ses, err := s.getSession(ctx, sessionID)
+	if errors.Is(err, notFound) {
+		return nil, nil
+	}
+	return ses, err
+}
+
+// Fetch one of the user's sessions along with the server-side remaining time.
+func (s *SessionManager) Get(ctx context.Context, sessionID string, userID string) (*SessionState, error) {
+	ses, err := s.getOwnedSession(ctx, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := ses.State(time.Now())
+	return &state, nil
+}
+
+func (s *SessionManager) AddAnswer(ctx context.Context, sessionID string, userID string, questionID string, response []string) error {
+	ses, err := s.getOwnedSession(ctx, sessionID, userID)
 	if err != nil {
 		return err
 	}
 
-	var ses Session
-	if err = json.Unmarshal(b, &ses); err != nil {
-		return errs.NewError(err, errs.DataErrorType, errs.ErrUndefined)
+	if !ses.AcceptsAnswers(time.Now(), s.GracePeriod) {
+		return ErrSessionExpired
 	}
 
-	if ses.Answers == nil {
-		ses.Answers = make(map[string]string)
+	if err := ses.ValidateAnswer(questionID, response...); err != nil {
+		return err
 	}
 
-	ses.Answers[questionID] = optionID
-	sesH, err := json.Marshal(&ses)
+	return s.recordAnswer(ctx, sessionID, questionID, response)
+}
+
+func (s *SessionManager) CalculateTotalMarks(ctx context.Context, sessionID string, userID string) (float64, error) {
+	ses, err := s.getOwnedSession(ctx, sessionID, userID)
 	if err != nil {
-		return errs.NewError(err, errs.DataErrorType, errs.ErrUndefined)
+		return 0, err
 	}
 
-	err = s.Redis.Client.Set(ctx, userID, sesH, time.Duration(ses.TTL)*time.Second).Err()
+	mck, err := s.loadMock(ctx, ses.MockID)
+	if err != nil {
+		return 0, err
+	}
+
+	total, _ := grade(mck, ses, s.ScorerFor(mck.Mock))
+	return total, nil
+}
+
+// Grade the session, persist it as an attempt and close the session.
+func (s *SessionManager) Submit(ctx context.Context, sessionID string, userID string) (*attempt.FullAttempt, error) {
+	ses, err := s.getOwnedSession(ctx, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mck, err := s.loadMock(ctx, ses.MockID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.submit(ctx, ses, mck)
+}
+
+// Shared by explicit submission and the expiry worker.
+func (s *SessionManager) submit(ctx context.Context, ses *Session, mck *mock.FullMock) (*attempt.FullAttempt, error) {
+	total, results := grade(mck, ses, s.ScorerFor(mck.Mock))
+
+	att := entities.Attempt{
+		ID:          uuid.NewString(),
+		SessionID:   ses.ID,
+		MockID:      ses.MockID,
+		UserID:      ses.UserID,
+		TotalMarks:  total,
+		MaxMarks:    maxMarks(sessionQuestions(mck, ses)),
+		StartedAt:   ses.StartedAt,
+		SubmittedAt: time.Now(),
+	}
+
+	answers := make([]entities.AttemptAnswer, 0, len(results))
+	for _, r := range results {
+		answers = append(answers, entities.AttemptAnswer{
+			ID:               uuid.NewString(),
+			AttemptID:        att.ID,
+			QuestionID:       r.QuestionID,
+			SelectedOptionID: r.SelectedOption,
+			Response:         encodeResponse(r.Response),
+			IsCorrect:        r.IsCorrect,
+			Points:           r.Points,
+		})
+	}
+
+	full, err := attempt.CreateAttempt(ctx, s.DB, att, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.Redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(ses.ID), answersKey(ses.ID))
+		pipe.ZRem(ctx, deadlinesKey, ses.ID)
+		pipe.HDel(ctx, userSessionsKey(ses.UserID), ses.MockID)
+		pipe.SRem(ctx, mockSessionsKey(ses.MockID), ses.ID)
+		return nil
+	})
+	if err = data.RedisErrorComparator(err); err != nil {
+		return nil, err
+	}
+
+	return full, nil
+}
+
+// Remove a session that never started, as if it had not been created.
+func (s *SessionManager) discard(ctx context.Context, ses Session) {
+	s.Redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(ses.ID))
+		pipe.ZRem(ctx, deadlinesKey, ses.ID)
+		pipe.HDel(ctx, userSessionsKey(ses.UserID), ses.MockID)
+		pipe.SRem(ctx, mockSessionsKey(ses.MockID), ses.ID)
+		return nil
+	})
+}
+
+func (s *SessionManager) getSession(ctx context.Context, sessionID string) (*Session, error) {
+	b, err := s.Redis.Client.Get(ctx, sessionKey(sessionID)).Bytes()
 	err = data.RedisErrorComparator(err)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var ses Session
+	if err = json.Unmarshal(b, &ses); err != nil {
+		return nil, errs.NewError(err, errs.DataErrorType, errs.ErrUndefined)
 	}
-	return nil
-}
-
-func (s *SessionManager) CalculateTotalMarks(ctx context.Context, db *sql.DB, mockID string, userID string) (int, error) {
-    mck, err := mock.GetMock(ctx, db, mockID)
-    if err != nil {
-        return 0, err
-    }
-
-    b, err := s.Redis.Client.Get(ctx, userID).Bytes()
-    err = data.RedisErrorComparator(err)
-    if err != nil {
-        return 0, err
-    }
-
-    var ses Session
-    if err = json.Unmarshal(b, &ses); err != nil {
-        return 0, errs.NewError(err, errs.DataErrorType, errs.ErrUndefined)
-    }
-
-    if ses.Answers == nil {
-        return 0, nil
-    }
-
-    total := 0
-    for _, q := range mck.Questions {
-        if optionID, ok := ses.Answers[q.ID]; ok {
-            if optionID == q.CorrectOptionID {
-                total += q.Points
-            } else {
-                total -= q.Points
-            }
-        }
-    }
-    return total, nil
-}
-
-
-func (s *SessionManager) GetAnswerResults(ctx context.Context, db *sql.DB, mockID string, userID string) ([]AnswerResult, error) {
-    mck, err := mock.GetMock(ctx, db, mockID)
-    if err != nil {
-        return nil, err
-    }
-
-    b, err := s.Redis.Client.Get(ctx, userID).Bytes()
-    err = data.RedisErrorComparator(err)
-    if err != nil {
-        return nil, err
-    }
-
-    var ses Session
-    if err = json.Unmarshal(b, &ses); err != nil {
-        return nil, errs.NewError(err, errs.DataErrorType, errs.ErrUndefined)
-    }
-
-    results := []AnswerResult{}
-
-    if ses.Answers == nil {
-        return results, nil 
-    }
-
-    for _, q := range mck.Questions {
-        selectedOption, answered := ses.Answers[q.ID]
-        isCorrect := answered && selectedOption == q.CorrectOptionID
-        results = append(results, AnswerResult{
-            QuestionID:    q.ID,
-            SelectedOption: selectedOption,
-            IsCorrect:     isCorrect,
-        })
-    }
-
-    return results, nil
+
+	ses.Answers, err = s.getAnswers(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &ses, nil
+}
+
+// Sessions of other users are reported as missing rather than forbidden.
+func (s *SessionManager) getOwnedSession(ctx context.Context, sessionID string, userID string) (*Session, error) {
+	ses, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ses.UserID != userID {
+		return nil, ErrSessionNotFound
+	}
+	return ses, nil
+}
+
+// Redis expiration for a session: until its deadline, plus grace period and retention.
+func (s *SessionManager) expiration(ses Session, now time.Time) time.Duration {
+	return ses.DeadlineAt.Add(s.GracePeriod + SessionRetention).Sub(now)
 }
@@ -0,0 +1,171 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+)
+
+/*
+* A response is the candidate's answer to one question, as a list of tokens:
+*   single_choice, true_false, multiple_select : the selected option IDs
+*   ordering : every option ID, in the chosen order
+*   numeric  : the number
+*   matching : "optionID=matchID" pairs, see entities.MockOption.MatchID
+ */
+
+// Build the response tokens from an answer request.
+func NewResponse(req schemas.AnswerAddRequest) []string {
+	switch {
+	case req.Value != nil:
+		return []string{strconv.FormatFloat(*req.Value, 'g', -1, 64)}
+
+	case len(req.Matches) > 0:
+		pairs := make([]string, 0, len(req.Matches))
+		for left, right := range req.Matches {
+			pairs = append(pairs, left+"="+right)
+		}
+		sort.Strings(pairs)
+		return pairs
+
+	case len(req.OptionIDs) > 0:
+		return req.OptionIDs
+
+	case req.OptionID != "":
+		return []string{req.OptionID}
+	}
+	return nil
+}
+
+func encodeResponse(response []string) string {
+	if len(response) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(response)
+	return string(b)
+}
+
+func decodeResponse(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var response []string
+	if json.Unmarshal([]byte(value), &response) != nil {
+		return nil
+	}
+	return response
+}
+
+func splitPair(token string) (string, string, bool) {
+	return strings.Cut(token, "=")
+}
+
+// Check a response against the question's type and options.
+func validateResponse(q SessionQuestion, response []string) error {
+	illegal := func(format string, args ...any) error {
+		return errs.NewError(fmt.Errorf(format, args...), errs.DataErrorType, errs.ErrDataIllegal)
+	}
+
+	if len(response) == 0 {
+		return illegal("empty answer to question %q", q.ID)
+	}
+
+	switch q.Type {
+	case entities.QuestionNumeric:
+		if len(response) != 1 {
+			return illegal("question %q takes a single number", q.ID)
+		}
+		v, err := strconv.ParseFloat(response[0], 64)
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+			return illegal("question %q takes a number, got %q", q.ID, response[0])
+		}
+		return nil
+
+	case entities.QuestionMatching:
+		seen := make(map[string]bool, len(response))
+		for _, token := range response {
+			left, right, ok := splitPair(token)
+			if !ok || !slices.Contains(q.OptionIDs, left) || !slices.Contains(q.MatchIDs, right) {
+				return illegal("invalid match %q for question %q", token, q.ID)
+			}
+			if seen[left] {
+				return illegal("option %q is matched twice", left)
+			}
+			seen[left] = true
+		}
+		return nil
+	}
+
+	seen := make(map[string]bool, len(response))
+	for _, optionID := range response {
+		if !slices.Contains(q.OptionIDs, optionID) {
+			return illegal("option %q does not belong to question %q", optionID, q.ID)
+		}
+		if seen[optionID] {
+			return illegal("option %q is selected twice", optionID)
+		}
+		seen[optionID] = true
+	}
+
+	switch q.Type {
+	case entities.QuestionSingleChoice, entities.QuestionTrueFalse:
+		if len(response) != 1 {
+			return illegal("question %q takes a single option", q.ID)
+		}
+	case entities.QuestionOrdering:
+		if len(response) != len(q.OptionIDs) {
+			return illegal("question %q must order all %d options", q.ID, len(q.OptionIDs))
+		}
+	}
+	return nil
+}
+
+// The response that earns full marks on a question.
+func correctResponse(q mock.FullMockQuestion) []string {
+	switch q.QuestionType() {
+	case entities.QuestionMultipleSelect:
+		correct := []string{}
+		for _, opt := range q.Options {
+			if opt.IsCorrect {
+				correct = append(correct, opt.ID)
+			}
+		}
+		return correct
+
+	case entities.QuestionNumeric:
+		if q.NumericAnswer == nil {
+			return nil
+		}
+		return []string{strconv.FormatFloat(*q.NumericAnswer, 'g', -1, 64)}
+
+	case entities.QuestionOrdering:
+		options := slices.Clone(q.Options)
+		sort.SliceStable(options, func(i, j int) bool { return options[i].Number < options[j].Number })
+
+		order := make([]string, len(options))
+		for i, opt := range options {
+			order[i] = opt.ID
+		}
+		return order
+
+	case entities.QuestionMatching:
+		pairs := make([]string, len(q.Options))
+		for i, opt := range q.Options {
+			pairs[i] = opt.ID + "=" + opt.MatchID
+		}
+		sort.Strings(pairs)
+		return pairs
+	}
+
+	return []string{q.CorrectOptionID}
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+)
+
+type AttemptResults struct {
+	Attempt  entities.Attempt `json:"attempt"`
+	Revealed bool             `json:"answers_revealed"`
+	Results  []AnswerResult   `json:"results"`
+}
+
+// Per-question breakdown of a submitted attempt.
+// The author of the mock always sees correct answers and explanations,
+// the candidate only when the mock's review policy allows it.
+func (s *SessionManager) GetAnswerResults(ctx context.Context, attemptID string, userID string) (*AttemptResults, error) {
+	att, err := attempt.GetAttempt(ctx, s.DB, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	mck, err := s.loadMock(ctx, att.MockID)
+	if err != nil {
+		return nil, err
+	}
+
+	isAuthor := mck.AuthorID == userID
+	if att.UserID != userID && !isAuthor {
+		return nil, errs.NewError(errors.New("attempt belongs to another user"), errs.DataErrorType, errs.ErrForbidden)
+	}
+
+	reveal := isAuthor || mck.ReviewPolicy == entities.ReviewAfterSubmit
+
+	return &AttemptResults{
+		Attempt:  att.Attempt,
+		Revealed: reveal,
+		Results:  buildResults(mck, att.Answers, reveal),
+	}, nil
+}
+
+// Questions removed from the mock since the attempt keep their recorded outcome.
+func buildResults(mck *mock.FullMock, answers []entities.AttemptAnswer, reveal bool) []AnswerResult {
+	questions := make(map[string]mock.FullMockQuestion, len(mck.Questions))
+	for _, q := range mck.Questions {
+		questions[q.ID] = q
+	}
+
+	results := make([]AnswerResult, 0, len(answers))
+	for _, a := range answers {
+		r := AnswerResult{
+			QuestionID:     a.QuestionID,
+			SelectedOption: a.SelectedOptionID,
+			Response:       decodeResponse(a.Response),
+			IsCorrect:      a.IsCorrect,
+			Points:         a.Points,
+		}
+
+		if q, ok := questions[a.QuestionID]; ok {
+			r.Problem = q.Problem
+			if reveal {
+				r.CorrectResponse = correctResponse(q)
+				if isSingleOption(q) {
+					r.CorrectOption = q.CorrectOptionID
+				}
+				r.Explanation = q.Explanation
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
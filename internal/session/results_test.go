@@ -0,0 +1,89 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/attempt"
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/schemas"
+	"github.com/ashtonx86/mocker/internal/session"
+	"github.com/ashtonx86/mocker/internal/testutil"
+)
+
+func TestGetAnswerResults(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	testutil.CreateUsers(t, db, "author", "candidate")
+	manager := session.NewSessionManager(db, nil)
+
+	submitted := func(policy string) string {
+		m, err := mock.CreateMock(ctx, db, schemas.MockCreateRequest{
+			Topic: "Review", Instructions: "i", TimeMins: 30, AuthorID: "author", ReviewPolicy: policy,
+			Questions: []schemas.MockQuestionSchema{{
+				Problem: "p", Points: 1, CorrectOptionNumber: 2, Explanation: "because",
+				Options: []schemas.MockOptionSchema{{Number: 1, Option: "a"}, {Number: 2, Option: "b"}},
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		full, err := mock.GetMock(ctx, db, m.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := full.Questions[0]
+
+		now := time.Now()
+		att := entities.Attempt{ID: m.ID + "-attempt", SessionID: m.ID + "-session", MockID: m.ID, UserID: "candidate", MaxMarks: 1, StartedAt: now.Add(-time.Minute), SubmittedAt: now}
+		answers := []entities.AttemptAnswer{{ID: m.ID + "-answer", AttemptID: att.ID, QuestionID: q.ID, SelectedOptionID: q.Options[0].ID, Response: `["` + q.Options[0].ID + `"]`}}
+		if _, err := attempt.CreateAttempt(ctx, db, att, answers); err != nil {
+			t.Fatal(err)
+		}
+		return att.ID
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		userID string
+		reveal bool
+	}{
+		{"candidate under never", entities.ReviewNever, "candidate", false},
+		{"author under never", entities.ReviewNever, "author", true},
+		{"candidate under after_submit", entities.ReviewAfterSubmit, "candidate", true},
+		{"author under after_submit", entities.ReviewAfterSubmit, "author", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := manager.GetAnswerResults(ctx, submitted(tt.policy), tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Revealed != tt.reveal || len(res.Results) != 1 {
+				t.Fatalf("expected answers_revealed=%v with one result, got %+v", tt.reveal, res)
+			}
+
+			r := res.Results[0]
+			if r.Problem != "p" || len(r.Response) != 1 || r.Response[0] != r.SelectedOption {
+				t.Errorf("expected the candidate's own answer whatever the policy, got %+v", r)
+			}
+			revealed := r.CorrectOption != "" || r.CorrectResponse != nil || r.Explanation != ""
+			if revealed != tt.reveal {
+				t.Errorf("expected the answer key revealed=%v, got %+v", tt.reveal, r)
+			}
+			if tt.reveal && (r.CorrectOption == r.SelectedOption || r.Explanation != "because") {
+				t.Errorf("expected the correct option and explanation, got %+v", r)
+			}
+		})
+	}
+
+	_, err := manager.GetAnswerResults(ctx, submitted(entities.ReviewAfterSubmit), "someone-else")
+	if !errors.Is(err, errs.Error{Code: errs.ErrForbidden, Type: errs.DataErrorType.String()}) {
+		t.Errorf("expected another user's attempt to be forbidden, got %v", err)
+	}
+}
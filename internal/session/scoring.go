@@ -0,0 +1,153 @@
+package session
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/mock"
+)
+
+// Scorer awards points for a single question.
+// selected holds the response tokens (see NewResponse), and is empty when the question was skipped.
+type Scorer interface {
+	Score(q mock.FullMockQuestion, selected []string) QuestionScore
+	// Applied to the sum of all question scores.
+	Total(sum float64) float64
+}
+
+type QuestionScore struct {
+	Points    float64
+	IsCorrect bool
+}
+
+// Scores according to the scoring policy stored on the mock.
+type PolicyScorer struct {
+	NegativeMarking float64 // Fraction of a question's points deducted for a wrong answer.
+	FloorAtZero     bool
+	PartialCredit   bool
+}
+
+// assert: PolicyScorer implements Scorer interface.
+var _ Scorer = PolicyScorer{}
+
+func NewPolicyScorer(m entities.Mock) Scorer {
+	return PolicyScorer{
+		NegativeMarking: m.NegativeMarking,
+		FloorAtZero:     m.FloorAtZero,
+		PartialCredit:   m.PartialCredit,
+	}
+}
+
+func (p PolicyScorer) Score(q mock.FullMockQuestion, selected []string) QuestionScore {
+	if len(selected) == 0 {
+		return QuestionScore{}
+	}
+
+	hits, misses, total := compare(q, selected)
+
+	if hits == total && misses == 0 {
+		return QuestionScore{Points: float64(q.Points), IsCorrect: true}
+	}
+
+	// Each correct part earns its share of the points, each wrong one takes a share back.
+	if p.PartialCredit && hits > misses {
+		share := float64(hits-misses) / float64(total)
+		return QuestionScore{Points: round(share * float64(q.Points))}
+	}
+
+	penalty := float64(q.Points) * p.NegativeMarking
+	if q.NegativePoints != nil {
+		penalty = *q.NegativePoints
+	}
+	return QuestionScore{Points: round(-penalty)}
+}
+
+func (p PolicyScorer) Total(sum float64) float64 {
+	if p.FloorAtZero && sum < 0 {
+		return 0
+	}
+	return round(sum)
+}
+
+// Count the right and wrong parts of a response, and how many parts a fully correct one has.
+func compare(q mock.FullMockQuestion, selected []string) (hits int, misses int, total int) {
+	correct := correctResponse(q)
+
+	switch q.QuestionType() {
+	case entities.QuestionNumeric:
+		v, err := strconv.ParseFloat(selected[0], 64)
+		if err == nil && q.NumericAnswer != nil && math.Abs(v-*q.NumericAnswer) <= q.Tolerance {
+			return 1, 0, 1
+		}
+		return 0, 1, 1
+
+	case entities.QuestionOrdering:
+		// Each option in its right place is a hit.
+		for i, id := range selected {
+			if i < len(correct) && correct[i] == id {
+				hits++
+			} else {
+				misses++
+			}
+		}
+		return hits, misses, len(correct)
+	}
+
+	// Choices are hits when they belong to the correct set, matching pairs likewise.
+	for _, id := range selected {
+		if slices.Contains(correct, id) {
+			hits++
+		} else {
+			misses++
+		}
+	}
+	return hits, misses, len(correct)
+}
+
+// Questions answered by picking exactly one option.
+func isSingleOption(q mock.FullMockQuestion) bool {
+	typ := q.QuestionType()
+	return typ == entities.QuestionSingleChoice || typ == entities.QuestionTrueFalse
+}
+
+// Keep fractional marks readable, e.g. 0.75 rather than 0.7499999999.
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// grade scores every question the session was given with the given scorer.
+func grade(mck *mock.FullMock, ses *Session, scorer Scorer) (float64, []AnswerResult) {
+	questions := sessionQuestions(mck, ses)
+
+	sum := 0.0
+	results := make([]AnswerResult, 0, len(questions))
+
+	for _, q := range questions {
+		selected := ses.Answers[q.ID]
+
+		score := scorer.Score(q, selected)
+		sum += score.Points
+
+		r := AnswerResult{
+			QuestionID: q.ID,
+			Response:   selected,
+			IsCorrect:  score.IsCorrect,
+			Points:     score.Points,
+		}
+		if isSingleOption(q) && len(selected) == 1 {
+			r.SelectedOption = selected[0]
+		}
+		results = append(results, r)
+	}
+	return scorer.Total(sum), results
+}
+
+func maxMarks(questions []mock.FullMockQuestion) int {
+	max := 0
+	for _, q := range questions {
+		max += q.Points
+	}
+	return max
+}
@@ -0,0 +1,123 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/session"
+)
+
+func testQuestion(points int) mock.FullMockQuestion {
+	return mock.FullMockQuestion{
+		MockQuestion: entities.MockQuestion{
+			ID:              "q1",
+			Points:          points,
+			CorrectOptionID: "right",
+		},
+	}
+}
+
+func TestPolicyScorer(t *testing.T) {
+	override := 0.5
+
+	cases := []struct {
+		name     string
+		policy   entities.Mock
+		question mock.FullMockQuestion
+		selected []string
+		expected float64
+	}{
+		{"correct", entities.Mock{NegativeMarking: 1}, testQuestion(4), []string{"right"}, 4},
+		{"skipped", entities.Mock{NegativeMarking: 1}, testQuestion(4), nil, 0},
+		{"wrong, full negative", entities.Mock{NegativeMarking: 1}, testQuestion(4), []string{"wrong"}, -4},
+		{"wrong, no negative", entities.Mock{NegativeMarking: 0}, testQuestion(4), []string{"wrong"}, 0},
+		{"wrong, quarter negative", entities.Mock{NegativeMarking: 0.25}, testQuestion(4), []string{"wrong"}, -1},
+		{"wrong, question override", entities.Mock{NegativeMarking: 1}, func() mock.FullMockQuestion {
+			q := testQuestion(4)
+			q.NegativePoints = &override
+			return q
+		}(), []string{"wrong"}, -0.5},
+	}
+
+	for _, c := range cases {
+		score := session.NewPolicyScorer(c.policy).Score(c.question, c.selected)
+		if score.Points != c.expected {
+			t.Errorf("%s :: expected %v points, got %v", c.name, c.expected, score.Points)
+		}
+	}
+}
+
+func TestPolicyScorerTotal(t *testing.T) {
+	floored := session.NewPolicyScorer(entities.Mock{FloorAtZero: true})
+	if total := floored.Total(-3); total != 0 {
+		t.Errorf("expected total floored at 0, got %v", total)
+	}
+
+	unfloored := session.NewPolicyScorer(entities.Mock{})
+	if total := unfloored.Total(-3); total != -3 {
+		t.Errorf("expected negative total to be kept, got %v", total)
+	}
+}
+
+func TestPolicyScorerQuestionTypes(t *testing.T) {
+	answer := 9.81
+	options := []entities.MockOption{
+		{ID: "a", Number: 1, IsCorrect: true, MatchID: "ma"},
+		{ID: "b", Number: 2, IsCorrect: true, MatchID: "mb"},
+		{ID: "c", Number: 3, MatchID: "mc"},
+	}
+	question := func(typ string) mock.FullMockQuestion {
+		return mock.FullMockQuestion{
+			MockQuestion: entities.MockQuestion{ID: "q", Type: typ, Points: 4, NumericAnswer: &answer, Tolerance: 0.01},
+			Options:      options,
+		}
+	}
+
+	strict := entities.Mock{NegativeMarking: 0}
+	partial := entities.Mock{NegativeMarking: 0, PartialCredit: true}
+
+	cases := []struct {
+		name     string
+		policy   entities.Mock
+		typ      string
+		selected []string
+		expected float64
+	}{
+		{"multiple select, all correct", strict, entities.QuestionMultipleSelect, []string{"b", "a"}, 4},
+		{"multiple select, one missing", strict, entities.QuestionMultipleSelect, []string{"a"}, 0},
+		{"multiple select, one missing, partial", partial, entities.QuestionMultipleSelect, []string{"a"}, 2},
+		{"numeric, within tolerance", strict, entities.QuestionNumeric, []string{"9.8"}, 4},
+		{"numeric, outside tolerance", strict, entities.QuestionNumeric, []string{"9.7"}, 0},
+		{"ordering, correct", strict, entities.QuestionOrdering, []string{"a", "b", "c"}, 4},
+		{"ordering, two swapped", strict, entities.QuestionOrdering, []string{"b", "a", "c"}, 0},
+		{"matching, correct", strict, entities.QuestionMatching, []string{"a=ma", "b=mb", "c=mc"}, 4},
+		{"matching, two of three, partial", partial, entities.QuestionMatching, []string{"a=ma", "b=mb"}, 2.67},
+	}
+
+	for _, c := range cases {
+		score := session.NewPolicyScorer(c.policy).Score(question(c.typ), c.selected)
+		if score.Points != c.expected {
+			t.Errorf("%s :: expected %v points, got %v", c.name, c.expected, score.Points)
+		}
+	}
+}
+
+func TestPolicyScorerMatchIDs(t *testing.T) {
+	q := mock.FullMockQuestion{
+		MockQuestion: entities.MockQuestion{ID: "q", Type: entities.QuestionMatching, Points: 2},
+		Options: []entities.MockOption{
+			{ID: "a", Number: 1, Match: "Sodium", MatchID: "ma"},
+			{ID: "b", Number: 2, Match: "Iron", MatchID: "mb"},
+		},
+	}
+	scorer := session.NewPolicyScorer(entities.Mock{NegativeMarking: 0})
+
+	if score := scorer.Score(q, []string{"a=ma", "b=mb"}); score.Points != 2 {
+		t.Errorf("expected full marks, got %v", score.Points)
+	}
+	// Options are paired with match IDs, never with each other.
+	if score := scorer.Score(q, []string{"a=a", "b=b"}); score.Points != 0 {
+		t.Errorf("expected no marks, got %v", score.Points)
+	}
+}
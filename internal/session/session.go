@@ -1,14 +1,157 @@
 package session
 
-import "time"
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+)
 
 type Session struct {
 	ID     string `json:"id"`
 	MockID string `json:"mock_id"`
 	UserID string `json:"user_id"`
 
-	TTL       int `json:"ttl"`
-	Answers   map[string]string // [K : questionID] [V : optionID/answerID]
-	
-	CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+	// Snapshot of the mock's structure taken when the session starts,
+	// so answers are validated without reloading the mock.
+	// Only the questions drawn from the mock's pools are in it, and only those are graded.
+	// It is in the order the candidate sees, see Shuffle.
+	Questions       []SessionQuestion `json:"questions"`
+	OptionsShuffled bool              `json:"options_shuffled,omitempty"`
+
+	// Loaded from the answers hash, never written as part of the session JSON.
+	Answers map[string][]string `json:"answers,omitempty"` // [K : questionID] [V : response, see NewResponse]
+
+	StartedAt  time.Time `json:"started_at"`
+	DeadlineAt time.Time `json:"deadline_at"` // Answers are refused after DeadlineAt + grace period.
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SessionQuestion struct {
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	OptionIDs []string `json:"option_ids"`
+	MatchIDs  []string `json:"match_ids,omitempty"` // Right-hand sides of a matching question.
+}
+
+// Session as seen by the client, with the clock owned by the server.
+type SessionState struct {
+	Session
+	RemainingSeconds int64     `json:"remaining_seconds"`
+	Expired          bool      `json:"expired"`
+	ServerTime       time.Time `json:"server_time"`
+}
+
+// Time left before the deadline, never negative.
+func (s Session) Remaining(now time.Time) time.Duration {
+	remaining := s.DeadlineAt.Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Whether answers can still be accepted at the given moment.
+func (s Session) AcceptsAnswers(now time.Time, grace time.Duration) bool {
+	return !now.After(s.DeadlineAt.Add(grace))
+}
+
+func (s Session) State(now time.Time) SessionState {
+	return SessionState{
+		Session:          s,
+		RemainingSeconds: int64(s.Remaining(now).Seconds()),
+		Expired:          !now.Before(s.DeadlineAt),
+		ServerTime:       now,
+	}
+}
+
+// Take the layout of one session: the questions outside pools, and from each
+// pool as many questions as it draws, picked at random. The mock's order is kept.
+func DrawLayout(mck *mock.FullMock) []SessionQuestion {
+	return layout(draw(mck))
+}
+
+func draw(mck *mock.FullMock) []mock.FullMockQuestion {
+	if len(mck.Pools) == 0 {
+		return mck.Questions
+	}
+
+	// [K : pool name] [V : indexes of its questions]
+	members := make(map[string][]int, len(mck.Pools))
+	for _, pool := range mck.Pools {
+		members[pool.Name] = []int{}
+	}
+
+	picked := make([]bool, len(mck.Questions))
+	for i, q := range mck.Questions {
+		if _, ok := members[q.Pool]; ok {
+			members[q.Pool] = append(members[q.Pool], i)
+		} else {
+			picked[i] = true
+		}
+	}
+
+	for _, pool := range mck.Pools {
+		idx := members[pool.Name]
+		for _, j := range rand.Perm(len(idx))[:min(pool.Draw, len(idx))] {
+			picked[idx[j]] = true
+		}
+	}
+
+	drawn := make([]mock.FullMockQuestion, 0, len(mck.Questions))
+	for i, q := range mck.Questions {
+		if picked[i] {
+			drawn = append(drawn, q)
+		}
+	}
+	return drawn
+}
+
+// The questions of the mock the session was given.
+func sessionQuestions(mck *mock.FullMock, ses *Session) []mock.FullMockQuestion {
+	given := make(map[string]bool, len(ses.Questions))
+	for _, q := range ses.Questions {
+		given[q.ID] = true
+	}
+
+	questions := make([]mock.FullMockQuestion, 0, len(ses.Questions))
+	for _, q := range mck.Questions {
+		if given[q.ID] {
+			questions = append(questions, q)
+		}
+	}
+	return questions
+}
+
+func layout(questions []mock.FullMockQuestion) []SessionQuestion {
+	layout := make([]SessionQuestion, 0, len(questions))
+	for _, q := range questions {
+		optionIDs := make([]string, 0, len(q.Options))
+		var matchIDs []string
+		for _, opt := range q.Options {
+			optionIDs = append(optionIDs, opt.ID)
+			if opt.Match != "" {
+				matchIDs = append(matchIDs, opt.MatchID)
+			}
+		}
+		layout = append(layout, SessionQuestion{
+			ID:        q.ID,
+			Type:      q.QuestionType(),
+			OptionIDs: optionIDs,
+			MatchIDs:  matchIDs,
+		})
+	}
+	return layout
+}
+
+// Check that the question belongs to this session and the response fits the question.
+func (s Session) ValidateAnswer(questionID string, response ...string) error {
+	for _, q := range s.Questions {
+		if q.ID == questionID {
+			return validateResponse(q, response)
+		}
+	}
+	return errs.NewError(fmt.Errorf("question %q is not part of this session", questionID), errs.DataErrorType, errs.ErrDataIllegal)
+}
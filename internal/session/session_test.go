@@ -0,0 +1,213 @@
+package session_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/errs"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/session"
+)
+
+func TestSessionClock(t *testing.T) {
+	now := time.Now()
+	ses := session.Session{
+		StartedAt:  now,
+		DeadlineAt: now.Add(10 * time.Minute),
+	}
+
+	if got := ses.Remaining(now.Add(4 * time.Minute)); got != 6*time.Minute {
+		t.Errorf("expected 6m remaining, got %v", got)
+	}
+	if got := ses.Remaining(now.Add(20 * time.Minute)); got != 0 {
+		t.Errorf("remaining time must not be negative, got %v", got)
+	}
+
+	grace := 30 * time.Second
+	if !ses.AcceptsAnswers(now.Add(10*time.Minute+10*time.Second), grace) {
+		t.Error("answer within the grace period was refused")
+	}
+	if ses.AcceptsAnswers(now.Add(11*time.Minute), grace) {
+		t.Error("answer after the grace period was accepted")
+	}
+}
+
+func TestValidateAnswer(t *testing.T) {
+	ses := session.Session{
+		Questions: []session.SessionQuestion{
+			{ID: "q1", Type: entities.QuestionSingleChoice, OptionIDs: []string{"q1o1", "q1o2"}},
+			{ID: "q2", Type: entities.QuestionSingleChoice, OptionIDs: []string{"q2o1", "q2o2"}},
+		},
+	}
+	illegal := errs.Error{Code: errs.ErrDataIllegal, Type: errs.DataErrorType.String()}
+
+	if err := ses.ValidateAnswer("q1", "q1o2"); err != nil {
+		t.Errorf("valid answer rejected :: %v", err)
+	}
+	if err := ses.ValidateAnswer("q3", "q1o1"); !errors.Is(err, illegal) {
+		t.Errorf("unknown question accepted :: %v", err)
+	}
+	if err := ses.ValidateAnswer("q1", "q2o1"); !errors.Is(err, illegal) {
+		t.Errorf("option of another question accepted :: %v", err)
+	}
+}
+
+func TestValidateAnswerByType(t *testing.T) {
+	options := []string{"o1", "o2", "o3"}
+	ses := session.Session{
+		Questions: []session.SessionQuestion{
+			{ID: "single", Type: entities.QuestionSingleChoice, OptionIDs: options},
+			{ID: "multi", Type: entities.QuestionMultipleSelect, OptionIDs: options},
+			{ID: "numeric", Type: entities.QuestionNumeric},
+			{ID: "ordering", Type: entities.QuestionOrdering, OptionIDs: options},
+			{ID: "matching", Type: entities.QuestionMatching, OptionIDs: options, MatchIDs: []string{"m1", "m2", "m3"}},
+		},
+	}
+
+	cases := []struct {
+		question string
+		response []string
+		valid    bool
+	}{
+		{"single", []string{"o1"}, true},
+		{"single", []string{"o1", "o2"}, false},
+		{"multi", []string{"o1", "o3"}, true},
+		{"multi", []string{"o1", "o1"}, false},
+		{"numeric", []string{"-2.5"}, true},
+		{"numeric", []string{"two"}, false},
+		{"ordering", []string{"o3", "o1", "o2"}, true},
+		{"ordering", []string{"o3", "o1"}, false},
+		{"matching", []string{"o1=m2", "o2=m1"}, true},
+		{"matching", []string{"o1=m2", "o1=m3"}, false},
+		{"matching", []string{"o1=o2", "o2=o1"}, false},
+		{"matching", []string{"o1"}, false},
+		{"single", nil, false},
+	}
+
+	for _, c := range cases {
+		err := ses.ValidateAnswer(c.question, c.response...)
+		if c.valid && err != nil {
+			t.Errorf("%s %v :: valid answer rejected :: %v", c.question, c.response, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s %v :: invalid answer accepted", c.question, c.response)
+		}
+	}
+}
+
+func TestDrawLayout(t *testing.T) {
+	mck := &mock.FullMock{
+		Pools: []entities.MockPool{{Name: "easy", Draw: 2}, {Name: "hard", Draw: 1}},
+	}
+	pools := map[string]string{"fixed1": "", "easy1": "easy", "easy2": "easy", "easy3": "easy", "hard1": "hard", "hard2": "hard", "fixed2": ""}
+	for _, id := range []string{"fixed1", "easy1", "easy2", "easy3", "hard1", "hard2", "fixed2"} {
+		mck.Questions = append(mck.Questions, mock.FullMockQuestion{MockQuestion: entities.MockQuestion{ID: id, Pool: pools[id]}})
+	}
+
+	seen := make(map[string]bool)
+	for range 50 {
+		layout := session.DrawLayout(mck)
+		if len(layout) != 5 || layout[0].ID != "fixed1" || layout[4].ID != "fixed2" {
+			t.Fatalf("expected both fixed questions around 3 drawn ones, got %+v", layout)
+		}
+
+		drawn := make(map[string]int)
+		for _, q := range layout[1:4] {
+			drawn[pools[q.ID]]++
+			seen[q.ID] = true
+		}
+		if drawn["easy"] != 2 || drawn["hard"] != 1 {
+			t.Fatalf("expected 2 easy and 1 hard question, got %v", drawn)
+		}
+	}
+	if len(seen) != 5 {
+		t.Errorf("expected every pooled question to be drawn at some point, got %v", seen)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	var layout []session.SessionQuestion
+	for i := range 10 {
+		id := string(rune('a' + i))
+		layout = append(layout, session.SessionQuestion{ID: id, OptionIDs: []string{id + "1", id + "2", id + "3", id + "4"}})
+	}
+	ids := func(l []session.SessionQuestion) string {
+		s := ""
+		for _, q := range l {
+			s += q.ID + strings.Join(q.OptionIDs, "") + " "
+		}
+		return s
+	}
+
+	first := session.Shuffle(layout, "session-1", true, true)
+	if ids(first) != ids(session.Shuffle(layout, "session-1", true, true)) {
+		t.Fatal("expected the same session ID to give the same order")
+	}
+	if ids(first) == ids(session.Shuffle(layout, "session-2", true, true)) {
+		t.Error("expected different session IDs to give different orders")
+	}
+	if ids(first) == ids(layout) {
+		t.Error("expected the order to change")
+	}
+
+	seen := make(map[string]bool)
+	for _, q := range first {
+		seen[q.ID] = true
+		if len(q.OptionIDs) != 4 {
+			t.Errorf("expected question %s to keep its options, got %v", q.ID, q.OptionIDs)
+		}
+	}
+	if len(seen) != len(layout) {
+		t.Errorf("expected every question once, got %v", seen)
+	}
+	if layout[0].ID != "a" || layout[0].OptionIDs[0] != "a1" {
+		t.Error("expected the original layout to be left alone")
+	}
+
+	kept := session.Shuffle(layout, "session-1", true, false)
+	for _, q := range kept {
+		if q.OptionIDs[0] != q.ID+"1" {
+			t.Errorf("expected options of %s to keep their order, got %v", q.ID, q.OptionIDs)
+		}
+	}
+	if ids(session.Shuffle(layout, "session-1", false, false)) != ids(layout) {
+		t.Error("expected no shuffling when both are off")
+	}
+}
+
+func TestShuffleOrdering(t *testing.T) {
+	layout := []session.SessionQuestion{
+		{ID: "pair", Type: entities.QuestionOrdering, OptionIDs: []string{"1", "2"}},
+		{ID: "four", Type: entities.QuestionOrdering, OptionIDs: []string{"1", "2", "3", "4"}},
+		{ID: "pick", Type: entities.QuestionSingleChoice, OptionIDs: []string{"1", "2", "3"}},
+	}
+
+	for i := range 200 {
+		sessionID := fmt.Sprintf("session-%d", i)
+		for _, options := range []bool{false, true} {
+			shuffled := session.Shuffle(layout, sessionID, false, options)
+			for j, q := range shuffled[:2] {
+				if slices.Equal(q.OptionIDs, layout[j].OptionIDs) {
+					t.Fatalf("%s: expected ordering question %s out of its solved order, got %v", sessionID, q.ID, q.OptionIDs)
+				}
+				if len(q.OptionIDs) != len(layout[j].OptionIDs) {
+					t.Fatalf("%s: expected question %s to keep its options, got %v", sessionID, q.ID, q.OptionIDs)
+				}
+			}
+			if !options && !slices.Equal(shuffled[2].OptionIDs, layout[2].OptionIDs) {
+				t.Fatalf("%s: expected other questions to keep their options' order, got %v", sessionID, shuffled[2].OptionIDs)
+			}
+		}
+	}
+
+	ses := session.Session{Questions: session.Shuffle(layout, "session", false, false)}
+	order := ses.Order()
+	if !slices.Equal(order[0].OptionIDs, ses.Questions[0].OptionIDs) || order[2].OptionIDs != nil {
+		t.Errorf("expected the candidate to see only the ordering questions' options in the session's order, got %+v", order)
+	}
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/mock"
+)
+
+/*
+* Reorder a layout for one session, the questions and/or the options of each
+* question. The order only depends on the session ID, but it is recorded in
+* the session all the same, so later changes to the mock do not move what the
+* candidate sees. Answers refer to option IDs, so grading is unaffected.
+*
+* The options of ordering questions are always shuffled, whatever options says:
+* the layout lists them in the mock's order, which is the answer. They are
+* never left in that order.
+ */
+func Shuffle(layout []SessionQuestion, sessionID string, questions bool, options bool) []SessionQuestion {
+	r := rand.New(rand.NewPCG(seed(sessionID)))
+
+	shuffled := slices.Clone(layout)
+	if questions {
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	}
+	for i := range shuffled {
+		q := &shuffled[i]
+		ordering := q.Type == entities.QuestionOrdering
+
+		canonical := q.OptionIDs
+		if options || ordering {
+			q.OptionIDs = slices.Clone(q.OptionIDs)
+			r.Shuffle(len(q.OptionIDs), func(i, j int) { q.OptionIDs[i], q.OptionIDs[j] = q.OptionIDs[j], q.OptionIDs[i] })
+		}
+		if options {
+			q.MatchIDs = slices.Clone(q.MatchIDs)
+			r.Shuffle(len(q.MatchIDs), func(i, j int) { q.MatchIDs[i], q.MatchIDs[j] = q.MatchIDs[j], q.MatchIDs[i] })
+		}
+		// Rotating takes every option off its place.
+		if ordering && len(canonical) > 1 && slices.Equal(q.OptionIDs, canonical) {
+			q.OptionIDs = append(slices.Clone(canonical[1:]), canonical[0])
+		}
+	}
+	return shuffled
+}
+
+func seed(sessionID string) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write([]byte(sessionID))
+	sum := h.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:])
+}
+
+// The order the candidate sees the mock in, see mock.FullMock.VisibleTo.
+// Options keep the mock's order unless the session shuffled them, as it
+// always does for ordering questions.
+func (s Session) Order() []mock.QuestionOrder {
+	order := make([]mock.QuestionOrder, 0, len(s.Questions))
+	for _, q := range s.Questions {
+		o := mock.QuestionOrder{ID: q.ID}
+		if s.OptionsShuffled {
+			o.OptionIDs, o.MatchIDs = q.OptionIDs, q.MatchIDs
+		} else if q.Type == entities.QuestionOrdering {
+			o.OptionIDs = q.OptionIDs
+		}
+		order = append(order, o)
+	}
+	return order
+}
@@ -2,24 +2,58 @@ package supervisor
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"os"
 	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ashtonx86/mocker/internal/data"
 	"github.com/ashtonx86/mocker/internal/entities"
+	"github.com/ashtonx86/mocker/internal/mock"
+	"github.com/ashtonx86/mocker/internal/search"
 	"github.com/ashtonx86/mocker/internal/session"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// Every table of the app, see Migrate.
+var Entities = []data.SQLEntity{
+	entities.User{},
+	entities.Mock{},
+	entities.MockQuestion{},
+	entities.MockOption{},
+	entities.MockTag{},
+	entities.MockInvitation{},
+	entities.MockPool{},
+	entities.BankQuestion{},
+	entities.BankOption{},
+	entities.MockBankQuestion{},
+	entities.Attempt{},
+	entities.AttemptAnswer{},
+}
+
+// Create or bring up to date every table of the app, one after another.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	for _, entity := range Entities {
+		if _, err := data.MigrateTable(ctx, db, entity); err != nil {
+			return fmt.Errorf("[pkg supervisor : func Migrate] failed to migrate %s :: %w", reflect.TypeOf(entity).Name(), err)
+		}
+	}
+	return nil
+}
+
 // Manage explicitly defined dependencies.
 type Supervisor struct {
 	SQLite *data.SQLite
 	SessionManager *session.SessionManager
+	MockCache *mock.Cache
+
+	// Cancels the background workers started by Init.
+	stopWorkers context.CancelFunc
 }
 
 // Initialize supervisor dependencies explicitly.
@@ -35,28 +69,66 @@ func New(ctx context.Context) (*Supervisor, error) {
 		return nil, err 
 	}
 
+	mockCache := mock.NewCache(sqlite.DB, redis)
+
 	sessionManagr := session.NewSessionManager(sqlite.DB, redis)
+	sessionManagr.Mocks = mockCache
+
+	if grace := os.Getenv("SESSION_GRACE_SECONDS"); grace != "" {
+		secs, err := strconv.Atoi(grace)
+		if err != nil || secs < 0 {
+			return nil, fmt.Errorf("[pkg supervisor : func New] invalid SESSION_GRACE_SECONDS %q", grace)
+		}
+		sessionManagr.GracePeriod = time.Duration(secs) * time.Second
+	}
 
 	return &Supervisor{
 		SQLite: sqlite,
 		SessionManager: sessionManagr,
+		MockCache: mockCache,
 	}, nil
 }
 
-func (su *Supervisor) Init() {
+// Fails when the SQLite driver was built without FTS5, see README.md.
+func (su *Supervisor) Init() error {
 	su.initSQLite()
+	if err := su.initSearch(); err != nil {
+		return err
+	}
+	su.startWorkers()
+	return nil
 }
 
-func (su *Supervisor) initSQLite() {
-	initialEntities := []data.SQLEntity{
-		entities.User{},
-		entities.Mock{},
-		entities.MockQuestion{},
-		entities.MockOption{},
+// Stop the background workers.
+func (su *Supervisor) Close() {
+	if su.stopWorkers != nil {
+		su.stopWorkers()
 	}
+}
+
+// The indexes are kept in sync by triggers on the app's tables, so every write needs FTS5 from here on.
+func (su *Supervisor) initSearch() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Second)
+	defer cancel()
+
+	if err := search.Init(ctx, su.SQLite.DB); err != nil {
+		return fmt.Errorf("[pkg supervisor : func initSearch] full-text search init failed :: %w", err)
+	}
+	return nil
+}
+
+func (su *Supervisor) startWorkers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	su.stopWorkers = cancel
+
+	go su.SessionManager.RunExpiryWorker(ctx, session.ExpiryScanInterval)
+	go su.MockCache.Listen(ctx)
+}
+
+func (su *Supervisor) initSQLite() {
 	var wg sync.WaitGroup
 
-	for _, entity := range initialEntities {
+	for _, entity := range Entities {
 		wg.Add(1)
 
 		go func() {
@@ -66,11 +138,11 @@ func (su *Supervisor) initSQLite() {
 			typ := reflect.TypeOf(entity)
 			slog.Info("Entity", "type", typ)
 
-			stmt, err := data.CreateTable(ctx, su.SQLite.DB, entity)
-			slog.Info("Table created", "stmt", stmt)
+			stmts, err := data.MigrateTable(ctx, su.SQLite.DB, entity)
+			slog.Info("Table migrated", "stmts", stmts)
 
 			if err != nil {
-				slog.Error("Failed to create table", "error", err)
+				slog.Error("Failed to migrate table", "error", err)
 			} 
 			wg.Done()
 		}()
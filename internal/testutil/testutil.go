@@ -0,0 +1,53 @@
+// Fixtures shared by the tests of several packages.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashtonx86/mocker/internal/data"
+	"github.com/ashtonx86/mocker/internal/supervisor"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Open a fresh database with every table of the app, removed when the test ends.
+func NewDB(tb testing.TB) *sql.DB {
+	db, err := data.OpenSQLite(filepath.Join(tb.TempDir(), "test.db"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	if err := supervisor.Migrate(context.Background(), db); err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+// Add users with the given IDs, so that rows referring to them pass the foreign keys.
+// Users that already exist are left alone.
+func CreateUsers(tb testing.TB, db *sql.DB, ids ...string) {
+	now := time.Now()
+	for _, id := range ids {
+		_, err := db.Exec(`INSERT OR IGNORE INTO user (id, name, email, passwordHash, createdAt, lastUpdatedAt) VALUES (?, ?, ?, '', ?, ?)`,
+			id, id, id+"@example.com", now, now)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// Add bare mocks with the given IDs, by "author", for rows that only need something to refer to.
+func CreateMocks(tb testing.TB, db *sql.DB, ids ...string) {
+	CreateUsers(tb, db, "author")
+	now := time.Now()
+	for _, id := range ids {
+		_, err := db.Exec(`INSERT INTO mock (id, topic, timeMins, authorID, createdAt, lastUpdatedAt) VALUES (?, ?, 30, 'author', ?, ?)`, id, id, now, now)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+}